@@ -0,0 +1,143 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// Default values applied by SetDefaults.
+const (
+	DefaultImage               = "backplane/json-server"
+	DefaultReplicas            = int32(1)
+	DefaultPort                = int32(3000)
+	DefaultServicePort         = int32(3000)
+	DefaultIDField             = "id"
+	DefaultForeignKeySuffix    = "Id"
+	DefaultStorageSize         = "1Gi"
+	DefaultSyncIntervalSeconds = int32(5)
+	DefaultGeneratedItems      = int32(5)
+)
+
+// SetDefaults fills every unset field of the spec with the value the
+// controller acts on, so the stored object shows the effective spec. The
+// image is left unset: it follows the operator-wide default image, which the
+// controller resolves on every reconcile. It is safe to call repeatedly.
+func SetDefaults(js *JsonServer) {
+	spec := &js.Spec
+
+	if js.Name != "" {
+		if js.Labels == nil {
+			js.Labels = map[string]string{}
+		}
+		setIfEmpty(js.Labels, "app.kubernetes.io/name", "json-server")
+		setIfEmpty(js.Labels, "app.kubernetes.io/instance", js.Name)
+	}
+
+	if spec.Replicas == nil {
+		replicas := DefaultReplicas
+		spec.Replicas = &replicas
+	}
+	if spec.Port == 0 {
+		spec.Port = DefaultPort
+	}
+
+	if spec.Server == nil {
+		spec.Server = &ServerSpec{}
+	}
+	if spec.Server.IDField == "" {
+		spec.Server.IDField = DefaultIDField
+	}
+	if spec.Server.ForeignKeySuffix == "" {
+		spec.Server.ForeignKeySuffix = DefaultForeignKeySuffix
+	}
+
+	if spec.Storage != nil {
+		if spec.Storage.Size.IsZero() {
+			spec.Storage.Size = resource.MustParse(DefaultStorageSize)
+		}
+		if spec.Storage.ReseedPolicy == "" {
+			spec.Storage.ReseedPolicy = ReseedOnConfigChange
+		}
+	}
+
+	if spec.Service == nil {
+		spec.Service = &ServiceSpec{}
+	}
+	if spec.Service.Type == "" {
+		spec.Service.Type = corev1.ServiceTypeClusterIP
+	}
+	if spec.Service.Port == 0 {
+		spec.Service.Port = DefaultServicePort
+	}
+
+	if spec.OpenAPI != nil && spec.OpenAPI.Items == 0 {
+		spec.OpenAPI.Items = DefaultGeneratedItems
+	}
+
+	if spec.DataFormat == "" {
+		spec.DataFormat = DataFormatJSON
+	}
+	for i := range spec.Collections {
+		if spec.Collections[i].Format == "" {
+			spec.Collections[i].Format = CollectionFormatJSON
+		}
+	}
+
+	if spec.DeletionPolicy == "" {
+		spec.DeletionPolicy = DeletionPolicyDelete
+	}
+
+	if len(spec.Schemas) > 0 && spec.SchemaEnforcement == "" {
+		spec.SchemaEnforcement = SchemaEnforcementDeny
+	}
+
+	if spec.Ingress != nil && spec.Ingress.Path == "" {
+		spec.Ingress.Path = "/"
+	}
+
+	if spec.Consistency == nil {
+		spec.Consistency = &ConsistencySpec{}
+	}
+	if spec.Consistency.Mode == "" {
+		spec.Consistency.Mode = ConsistencyIndependent
+	}
+	if spec.Consistency.Mode == ConsistencySingleWriter && spec.Consistency.SyncIntervalSeconds == 0 {
+		spec.Consistency.SyncIntervalSeconds = DefaultSyncIntervalSeconds
+	}
+}
+
+// SplitImage splits an image reference into repository, tag and digest.
+func SplitImage(ref string) (repo, tag, digest string) {
+	if i := strings.Index(ref, "@"); i >= 0 {
+		ref, digest = ref[:i], ref[i+1:]
+	}
+	// A colon after the last slash separates the tag; one before it is a registry port.
+	if i := strings.LastIndex(ref, ":"); i > strings.LastIndex(ref, "/") {
+		ref, tag = ref[:i], ref[i+1:]
+	}
+	return ref, tag, digest
+}
+
+func setIfEmpty(m map[string]string, key, value string) {
+	if _, ok := m[key]; !ok {
+		m[key] = value
+	}
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	openapierrors "k8s.io/kube-openapi/pkg/validation/errors"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"k8s.io/kube-openapi/pkg/validation/strfmt"
+	"k8s.io/kube-openapi/pkg/validation/validate"
+)
+
+// ParseSchema parses a JSON Schema of spec.schemas.
+func ParseSchema(data string) (*spec.Schema, error) {
+	schema := &spec.Schema{}
+	if err := json.Unmarshal([]byte(data), schema); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+// ValidateSchemas matches every item of the collections in data against the
+// schema of its collection. Errors name the failing field and its JSON
+// pointer in db.json. Only the first maxDBErrors errors are returned, and
+// data that is not a JSON object is left to ValidateDB.
+func ValidateSchemas(data []byte, schemas map[string]*spec.Schema, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	var db map[string]any
+	if len(schemas) == 0 || json.Unmarshal(data, &db) != nil {
+		return errs
+	}
+
+	names := make([]string, 0, len(schemas))
+	for name := range schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		validator := validate.NewSchemaValidator(schemas[name], nil, "", strfmt.Default)
+		pointer := "/" + escapePointer(name)
+
+		switch value := db[name].(type) {
+		case []any:
+			for i, item := range value {
+				result := validator.Validate(item)
+				errs = append(errs, schemaErrors(result, fldPath.Child(name).Index(i),
+					pointer+"/"+strconv.Itoa(i))...)
+			}
+		case map[string]any:
+			errs = append(errs, schemaErrors(validator.Validate(value), fldPath.Child(name), pointer)...)
+		}
+	}
+
+	if len(errs) > maxDBErrors {
+		errs = errs[:maxDBErrors]
+	}
+	return errs
+}
+
+// schemaErrors converts the errors of one item. Validation errors name the
+// failing property as a dotted path, e.g. tags[0] or address.city, and are
+// sorted by path since the validator reports them in map order.
+func schemaErrors(result *validate.Result, itemPath *field.Path, itemPointer string) field.ErrorList {
+	var errs field.ErrorList
+	for _, err := range result.Errors {
+		fldPath, pointer, message := itemPath, itemPointer, err.Error()
+
+		if v, ok := err.(*openapierrors.Validation); ok {
+			message = strings.TrimPrefix(message, v.Name+" ")
+			// Required properties are named relative to the item, e.g. .name.
+			if name := strings.TrimPrefix(v.Name, "."); name != "" {
+				fldPath = itemPath.Child(name)
+				pointer = itemPointer + propertyPointer(name)
+			}
+		}
+		message = strings.TrimPrefix(strings.TrimSpace(message), "in body ")
+
+		errs = append(errs, field.Invalid(fldPath, field.OmitValueType{},
+			fmt.Sprintf("does not match the schema at %s: %s", pointer, message)))
+	}
+	sort.SliceStable(errs, func(i, j int) bool {
+		return errs[i].Field < errs[j].Field
+	})
+	return errs
+}
+
+// propertyPointer turns a dotted property path into a JSON pointer suffix,
+// e.g. tags[0] into /tags/0.
+func propertyPointer(name string) string {
+	name = strings.NewReplacer("[", ".", "]", "").Replace(name)
+
+	var pointer strings.Builder
+	for token := range strings.SplitSeq(name, ".") {
+		if token != "" {
+			pointer.WriteString("/" + escapePointer(token))
+		}
+	}
+	return pointer.String()
+}
+
+// escapePointer escapes a JSON pointer reference token (RFC 6901).
+func escapePointer(token string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+}
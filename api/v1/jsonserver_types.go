@@ -17,6 +17,8 @@ limitations under the License.
 package v1
 
 import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -30,8 +32,443 @@ type JsonServerSpec struct {
 	// The following markers will use OpenAPI v3 schema to validate the value
 	// More info: https://book.kubebuilder.io/reference/markers/crd-validation.html
 
-	Replicas   *int32 `json:"replicas,omitempty"`
-	JsonConfig string `json:"jsonConfig"`
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// JsonConfig is the inline db.json. At most one of jsonConfig, dataFrom
+	// and openAPI may be set, and one of them or collections is required.
+	// +optional
+	JsonConfig string `json:"jsonConfig,omitempty"`
+
+	// DataFormat is the format of jsonConfig and of the data read through
+	// dataFrom. Other formats are converted to JSON before they are served.
+	// Defaults to json.
+	// +optional
+	DataFormat DataFormat `json:"dataFormat,omitempty"`
+
+	// DataFrom reads db.json from a key of a ConfigMap or Secret in the same
+	// namespace. Changes to the referenced object roll the pods.
+	// +optional
+	DataFrom *DataSource `json:"dataFrom,omitempty"`
+
+	// OpenAPI generates db.json, and routes that mirror the API paths, from
+	// an OpenAPI 3 document in a ConfigMap.
+	// +optional
+	OpenAPI *OpenAPISource `json:"openAPI,omitempty"`
+
+	// Collections adds top-level collections to db.json, each from its own
+	// source. Names must be unique and must not repeat a key of jsonConfig,
+	// dataFrom or openAPI.
+	// +optional
+	Collections []CollectionSource `json:"collections,omitempty"`
+
+	// Generate adds collections of synthetic items to db.json. Names share
+	// the rules of collections.
+	// +optional
+	Generate []GenerateSpec `json:"generate,omitempty"`
+
+	// Schemas maps a collection name to the JSON Schema every item of the
+	// collection must match. A singular (object) resource is matched as a
+	// whole.
+	// +optional
+	Schemas map[string]SchemaSource `json:"schemas,omitempty"`
+
+	// SchemaEnforcement decides what happens to data that does not match
+	// spec.schemas. Defaults to Deny when schemas are set.
+	// +optional
+	SchemaEnforcement SchemaEnforcement `json:"schemaEnforcement,omitempty"`
+
+	// Port is the port json-server listens on inside the pod. Defaults to 3000.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	Port int32 `json:"port,omitempty"`
+
+	// Image overrides the operator-wide default json-server image.
+	// +optional
+	Image *ImageSpec `json:"image,omitempty"`
+
+	// Server configures json-server command-line options.
+	// +optional
+	Server *ServerSpec `json:"server,omitempty"`
+
+	// Storage keeps db.json on a PersistentVolumeClaim so writes made through
+	// the REST API survive pod restarts. When unset, data is served read-only
+	// from the ConfigMap and lost on restart.
+	// +optional
+	Storage *StorageSpec `json:"storage,omitempty"`
+
+	// Consistency decides how data stays consistent across replicas.
+	// +optional
+	Consistency *ConsistencySpec `json:"consistency,omitempty"`
+
+	// Service configures the Service that exposes the instance.
+	// +optional
+	Service *ServiceSpec `json:"service,omitempty"`
+
+	// Ingress exposes the instance outside the cluster through an Ingress or,
+	// when a Gateway is referenced, a Gateway API HTTPRoute.
+	// +optional
+	Ingress *IngressSpec `json:"ingress,omitempty"`
+
+	// DeletionPolicy decides what happens to the data and child resources
+	// when the JsonServer is deleted. Defaults to Delete.
+	// +optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+
+	// DeletionProtection makes the webhook reject deletes of the JsonServer.
+	// The protected annotation has the same effect.
+	// +optional
+	DeletionProtection bool `json:"deletionProtection,omitempty"`
+}
+
+// DataSource references JSON data held in another object of the namespace.
+// Exactly one reference must be set.
+type DataSource struct {
+	// ConfigMapKeyRef selects a key of a ConfigMap.
+	// +optional
+	ConfigMapKeyRef *corev1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+
+	// SecretKeyRef selects a key of a Secret labelled
+	// json-server.example.com/data-source=true. The data is served from a
+	// Secret owned by the JsonServer instead of its ConfigMap.
+	// +optional
+	SecretKeyRef *corev1.SecretKeySelector `json:"secretKeyRef,omitempty"`
+}
+
+// OpenAPISource generates example data from an OpenAPI 3 document.
+type OpenAPISource struct {
+	// ConfigMapKeyRef selects the ConfigMap key holding the document as JSON
+	// or YAML.
+	ConfigMapKeyRef corev1.ConfigMapKeySelector `json:"configMapKeyRef"`
+
+	// Items is the number of items generated for each resource. Defaults to 5.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=1000
+	// +optional
+	Items int32 `json:"items,omitempty"`
+
+	// Seed makes the data deterministic: the same document, items and seed
+	// always generate the same db.json. See RegenerateAnnotation.
+	// +optional
+	Seed int64 `json:"seed,omitempty"`
+}
+
+// GenerateSpec generates a collection of synthetic items.
+type GenerateSpec struct {
+	// Name is the key of the collection in db.json.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Count is the number of items. Items get the ids 1 to count.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100000
+	Count int32 `json:"count"`
+
+	// Seed makes the items deterministic: the same spec always generates
+	// the same items.
+	// +optional
+	Seed int64 `json:"seed,omitempty"`
+
+	// Fields maps a property to its template: text with placeholders such
+	// as {{name}}, {{firstName}}, {{lastName}}, {{email}}, {{uuid}},
+	// {{word}}, {{sentence}}, {{bool}}, {{index}}, {{int 1 100}},
+	// {{float 0 9.99}}, {{date 2024-01-01 2024-12-31}}, {{pick red green}}
+	// and {{ref users}}, the id of a random item of another collection.
+	// +optional
+	Fields map[string]string `json:"fields,omitempty"`
+}
+
+// SchemaSource is a JSON Schema. Exactly one of inline and configMapKeyRef
+// must be set.
+type SchemaSource struct {
+	// Inline is the JSON Schema as JSON.
+	// +optional
+	Inline string `json:"inline,omitempty"`
+
+	// ConfigMapKeyRef selects a ConfigMap key holding the JSON Schema.
+	// +optional
+	ConfigMapKeyRef *corev1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+}
+
+// DataFormat is the format db.json is written in.
+// +kubebuilder:validation:Enum=json;json5;jsonc;yaml
+type DataFormat string
+
+const (
+	// DataFormatJSON is plain JSON, served as written.
+	DataFormatJSON DataFormat = "json"
+	// DataFormatJSON5 is JSON5: JSONC plus unquoted keys, single-quoted
+	// strings and hexadecimal numbers, among others.
+	DataFormatJSON5 DataFormat = "json5"
+	// DataFormatJSONC is JSON with comments and trailing commas.
+	DataFormatJSONC DataFormat = "jsonc"
+	// DataFormatYAML is YAML.
+	DataFormatYAML DataFormat = "yaml"
+)
+
+// SchemaEnforcement decides how items that do not match their schema are handled.
+// +kubebuilder:validation:Enum=Warn;Deny
+type SchemaEnforcement string
+
+const (
+	// SchemaEnforcementWarn admits and serves the data, returning admission
+	// warnings and setting SchemaValid to False.
+	SchemaEnforcementWarn SchemaEnforcement = "Warn"
+	// SchemaEnforcementDeny rejects the data in the webhook and does not
+	// roll it out, setting ConfigValid to False.
+	SchemaEnforcementDeny SchemaEnforcement = "Deny"
+)
+
+// DeletionPolicy decides how a JsonServer is cleaned up on deletion.
+// +kubebuilder:validation:Enum=Delete;Snapshot;Retain
+type DeletionPolicy string
+
+const (
+	// DeletionPolicyDelete deletes all child resources with the JsonServer.
+	DeletionPolicyDelete DeletionPolicy = "Delete"
+	// DeletionPolicySnapshot saves the live data to a snapshot ConfigMap,
+	// which is kept, before the child resources are deleted.
+	DeletionPolicySnapshot DeletionPolicy = "Snapshot"
+	// DeletionPolicyRetain keeps the PersistentVolumeClaim and the Services
+	// for debugging and deletes the other child resources.
+	DeletionPolicyRetain DeletionPolicy = "Retain"
+)
+
+// CollectionSource is one top-level collection of db.json. Exactly one of
+// inline and configMapKeyRef must be set.
+type CollectionSource struct {
+	// Name is the key of the collection in db.json and its REST path, e.g. users.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Inline is the collection, usually an array of items.
+	// +optional
+	Inline string `json:"inline,omitempty"`
+
+	// ConfigMapKeyRef selects a ConfigMap key holding the collection.
+	// +optional
+	ConfigMapKeyRef *corev1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+
+	// Format is the format of the collection. Defaults to json.
+	// +optional
+	Format CollectionFormat `json:"format,omitempty"`
+}
+
+// CollectionFormat is the format a collection is written in.
+// +kubebuilder:validation:Enum=json;csv;ndjson
+type CollectionFormat string
+
+const (
+	// CollectionFormatJSON is a JSON array or object.
+	CollectionFormatJSON CollectionFormat = "json"
+	// CollectionFormatCSV is CSV with a header row naming the fields of
+	// each item. Numbers and booleans are converted from text, and empty
+	// cells are left out of the item.
+	CollectionFormatCSV CollectionFormat = "csv"
+	// CollectionFormatNDJSON is newline-delimited JSON, one item object per
+	// line.
+	CollectionFormatNDJSON CollectionFormat = "ndjson"
+)
+
+// ImageSpec configures the json-server container image.
+// Unset fields fall back to the operator's --default-image.
+type ImageSpec struct {
+	// Repository is the image name without tag or digest,
+	// e.g. registry.internal:5000/mirror/json-server.
+	// +optional
+	Repository string `json:"repository,omitempty"`
+
+	// Tag is the image tag. Ignored when Digest is set.
+	// +optional
+	Tag string `json:"tag,omitempty"`
+
+	// Digest pins the image by content digest.
+	// +kubebuilder:validation:Pattern=`^sha256:[a-f0-9]{64}$`
+	// +optional
+	Digest string `json:"digest,omitempty"`
+
+	// PullPolicy is the container image pull policy.
+	// +kubebuilder:validation:Enum=Always;IfNotPresent;Never
+	// +optional
+	PullPolicy corev1.PullPolicy `json:"pullPolicy,omitempty"`
+
+	// ImagePullSecrets are added to the pod spec for private registries.
+	// +optional
+	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+}
+
+// ServerSpec configures json-server command-line options. Routes and
+// middlewares are written into the ConfigMap next to db.json.
+type ServerSpec struct {
+	// Routes are custom rewrite rules written to routes.json,
+	// e.g. "/api/*": "/$1".
+	// +optional
+	Routes map[string]string `json:"routes,omitempty"`
+
+	// Middlewares maps a file name ending in .js to its JavaScript source.
+	// Files are passed to --middlewares in file name order.
+	// +optional
+	Middlewares map[string]string `json:"middlewares,omitempty"`
+
+	// DelayMs adds a delay in milliseconds to every response.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	DelayMs *int32 `json:"delayMs,omitempty"`
+
+	// ReadOnly allows only GET requests.
+	// +optional
+	ReadOnly bool `json:"readOnly,omitempty"`
+
+	// IDField is the property json-server uses as the item id. Defaults to "id".
+	// +optional
+	IDField string `json:"idField,omitempty"`
+
+	// ForeignKeySuffix is the suffix of foreign key properties. Defaults to "Id".
+	// +optional
+	ForeignKeySuffix string `json:"foreignKeySuffix,omitempty"`
+}
+
+// ReseedPolicy decides when the seed data from jsonConfig overwrites the
+// stored db.json.
+// +kubebuilder:validation:Enum=Never;OnConfigChange;Always
+type ReseedPolicy string
+
+const (
+	// ReseedNever seeds db.json only when the volume holds no data yet.
+	ReseedNever ReseedPolicy = "Never"
+	// ReseedOnConfigChange reseeds db.json when jsonConfig changes.
+	ReseedOnConfigChange ReseedPolicy = "OnConfigChange"
+	// ReseedAlways reseeds db.json every time a pod starts.
+	ReseedAlways ReseedPolicy = "Always"
+)
+
+// StorageSpec configures the PersistentVolumeClaim holding db.json.
+type StorageSpec struct {
+	// StorageClassName is the storage class of the claim. Immutable.
+	// Uses the cluster default when unset.
+	// +optional
+	StorageClassName *string `json:"storageClassName,omitempty"`
+
+	// Size is the requested capacity. It can grow but not shrink.
+	// +kubebuilder:default="1Gi"
+	// +optional
+	Size resource.Quantity `json:"size,omitempty"`
+
+	// ReseedPolicy decides when jsonConfig overwrites the stored data.
+	// +kubebuilder:default=OnConfigChange
+	// +optional
+	ReseedPolicy ReseedPolicy `json:"reseedPolicy,omitempty"`
+}
+
+// ConsistencyMode decides how replicas share data.
+// +kubebuilder:validation:Enum=Independent;ReadOnlyWhenScaled;SingleWriter
+type ConsistencyMode string
+
+const (
+	// ConsistencyIndependent gives every replica its own copy of the data.
+	// A write is only visible on the replica that received it.
+	ConsistencyIndependent ConsistencyMode = "Independent"
+	// ConsistencyReadOnlyWhenScaled runs json-server with --read-only
+	// whenever more than one replica is requested.
+	ConsistencyReadOnlyWhenScaled ConsistencyMode = "ReadOnlyWhenScaled"
+	// ConsistencySingleWriter runs a separate writer pod that holds the data.
+	// Replicas forward writes to it and periodically sync its /db.
+	ConsistencySingleWriter ConsistencyMode = "SingleWriter"
+)
+
+// ConsistencySpec configures data consistency across replicas.
+type ConsistencySpec struct {
+	// Mode decides how replicas share data.
+	// +kubebuilder:default=Independent
+	// +optional
+	Mode ConsistencyMode `json:"mode,omitempty"`
+
+	// SyncIntervalSeconds is how often replicas copy /db from the writer
+	// in SingleWriter mode.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=5
+	// +optional
+	SyncIntervalSeconds int32 `json:"syncIntervalSeconds,omitempty"`
+}
+
+// ServiceSpec configures the Service in front of the json-server pods.
+type ServiceSpec struct {
+	// Type is the Service type.
+	// +kubebuilder:validation:Enum=ClusterIP;NodePort;LoadBalancer
+	// +kubebuilder:default=ClusterIP
+	// +optional
+	Type corev1.ServiceType `json:"type,omitempty"`
+
+	// Port is the port the Service exposes. Defaults to 3000.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	Port int32 `json:"port,omitempty"`
+
+	// NodePort pins the node port for NodePort and LoadBalancer Services.
+	// One is allocated when unset.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	NodePort int32 `json:"nodePort,omitempty"`
+
+	// Annotations are added to the Service, e.g. for a cloud load balancer.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// Labels are added to the Service.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// IngressSpec configures external access to the instance.
+type IngressSpec struct {
+	// Host is the public host name, e.g. people.mock.example.com.
+	// +kubebuilder:validation:MinLength=1
+	Host string `json:"host"`
+
+	// Path is the path prefix routed to the instance. Defaults to "/".
+	// +kubebuilder:validation:Pattern=`^/`
+	// +optional
+	Path string `json:"path,omitempty"`
+
+	// ClassName is the IngressClass of the Ingress. Uses the cluster default when unset.
+	// +optional
+	ClassName *string `json:"className,omitempty"`
+
+	// TLSSecretName is the Secret holding the certificate for Host. The
+	// Ingress terminates TLS when set. Not supported with Gateway, where the
+	// Gateway listener terminates TLS.
+	// +optional
+	TLSSecretName string `json:"tlsSecretName,omitempty"`
+
+	// Annotations are added to the Ingress or HTTPRoute.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// Gateway attaches a Gateway API HTTPRoute to this Gateway instead of
+	// creating an Ingress. Requires the Gateway API CRDs.
+	// +optional
+	Gateway *GatewayReference `json:"gateway,omitempty"`
+}
+
+// GatewayReference names the Gateway an HTTPRoute attaches to.
+type GatewayReference struct {
+	// Name of the Gateway.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Namespace of the Gateway. Defaults to the namespace of the JsonServer.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// SectionName selects a single listener of the Gateway.
+	// +optional
+	SectionName string `json:"sectionName,omitempty"`
+
+	// HTTPS reports the public URL with https, for listeners that terminate TLS.
+	// +optional
+	HTTPS bool `json:"https,omitempty"`
 }
 
 // JsonServerStatus defines the observed state of JsonServer.
@@ -41,14 +478,243 @@ type JsonServerStatus struct {
 
 	// For Kubernetes API conventions, see:
 	// https://github.com/kubernetes/community/blob/master/contributors/devel/sig-architecture/api-conventions.md#typical-status-properties
+
+	// State is a one-word summary derived from Conditions (Synced or Error).
 	State   string `json:"state,omitempty"`
 	Message string `json:"message,omitempty"`
 
 	// Replicas is the current number of replicas
 	// +optional
 	Replicas int32 `json:"replicas,omitempty"`
+
+	// ObservedGeneration is the metadata.generation last processed by the controller.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Collections lists the top-level collections of the served db.json.
+	// +optional
+	Collections []CollectionStatus `json:"collections,omitempty"`
+
+	// ConfigHash identifies the rendered ConfigMap that new pods serve.
+	// +optional
+	ConfigHash string `json:"configHash,omitempty"`
+
+	// ReplicaSets lists the ReplicaSets that run pods and the config hash
+	// each of them serves. Entries differ while a rollout is in progress.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	ReplicaSets []ReplicaSetStatus `json:"replicaSets,omitempty"`
+
+	// URL is the public URL of the instance when spec.ingress is set.
+	// +optional
+	URL string `json:"url,omitempty"`
+
+	// Generated reports the data generated from spec.openAPI.
+	// +optional
+	Generated *GeneratedStatus `json:"generated,omitempty"`
+
+	// LastSnapshot describes the last snapshot of the live data.
+	// +optional
+	LastSnapshot *SnapshotStatus `json:"lastSnapshot,omitempty"`
+
+	// Conditions represent the latest available observations of the JsonServer.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// CollectionStatus reports a top-level collection of db.json.
+type CollectionStatus struct {
+	// Name of the collection.
+	Name string `json:"name"`
+
+	// Source is where the collection came from, e.g. spec.jsonConfig or
+	// ConfigMap users/users.json.
+	Source string `json:"source"`
+
+	// Items is the number of items of an array collection, or 1 for an
+	// object (singular) resource.
+	Items int32 `json:"items"`
+
+	// SkippedRows is the number of CSV or NDJSON rows that could not be
+	// converted and are not served.
+	// +optional
+	SkippedRows int32 `json:"skippedRows,omitempty"`
+
+	// RowErrors explains why the first skipped rows were skipped.
+	// +optional
+	// +listType=atomic
+	RowErrors []RowError `json:"rowErrors,omitempty"`
+}
+
+// RowError is a CSV or NDJSON row that could not be converted to an item.
+type RowError struct {
+	// Line is the line of the row in the source, counting from 1.
+	Line int32 `json:"line"`
+
+	// Message explains what is wrong with the row.
+	Message string `json:"message"`
+}
+
+// ReplicaSetStatus reports the config a ReplicaSet of the instance serves.
+type ReplicaSetStatus struct {
+	// Name of the ReplicaSet.
+	Name string `json:"name"`
+
+	// ConfigHash is the config hash in the pod template of the ReplicaSet.
+	// +optional
+	ConfigHash string `json:"configHash,omitempty"`
+
+	// Replicas is the number of pods of the ReplicaSet.
+	Replicas int32 `json:"replicas"`
+
+	// ReadyReplicas is the number of ready pods of the ReplicaSet.
+	ReadyReplicas int32 `json:"readyReplicas"`
+}
+
+// SnapshotStatus describes a snapshot of the data served by json-server.
+type SnapshotStatus struct {
+	// Request is the value of the snapshot annotation that was handled.
+	// +optional
+	Request string `json:"request,omitempty"`
+
+	// ConfigMapName is the ConfigMap holding the snapshot under db.json.
+	// +optional
+	ConfigMapName string `json:"configMapName,omitempty"`
+
+	// TakenAt is when the data was read from the running instance.
+	// +optional
+	TakenAt *metav1.Time `json:"takenAt,omitempty"`
+
+	// Message reports why the last snapshot or promotion attempt failed.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// GeneratedStatus reports data generated from an OpenAPI document.
+type GeneratedStatus struct {
+	// Source is the ConfigMap key the document was read from.
+	Source string `json:"source"`
+
+	// Seed is the seed the data was generated with, derived from
+	// spec.openAPI.seed and the regenerate annotation.
+	Seed int64 `json:"seed"`
+
+	// Regenerate is the value of the regenerate annotation the data was
+	// generated for.
+	// +optional
+	Regenerate string `json:"regenerate,omitempty"`
+
+	// Resources lists the generated collections and their API paths.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Resources []GeneratedResource `json:"resources,omitempty"`
+}
+
+// GeneratedResource is a collection generated from an OpenAPI document.
+type GeneratedResource struct {
+	// Name of the collection in db.json.
+	Name string `json:"name"`
+
+	// Path is the API path routed to the collection, e.g. /api/v1/users.
+	Path string `json:"path"`
 }
 
+// Annotations, labels and finalizers used by the controller.
+const (
+	// SnapshotAnnotation requests a snapshot of the live data. Set it to a new
+	// value, e.g. the current time, to take another snapshot.
+	SnapshotAnnotation = "json-server.example.com/snapshot"
+
+	// PromoteSnapshotAnnotation names a snapshot ConfigMap whose data replaces
+	// spec.jsonConfig. The controller removes the annotation once applied.
+	PromoteSnapshotAnnotation = "json-server.example.com/promote-snapshot"
+
+	// RegenerateAnnotation requests new data from spec.openAPI. Set it to a
+	// new value, e.g. the current time, to mix it into the seed; the data
+	// stays deterministic for a given value.
+	RegenerateAnnotation = "json-server.example.com/regenerate"
+
+	// ConfigHashAnnotation is set on the pod template to the hash of the
+	// rendered ConfigMap, so a data change rolls the pods.
+	ConfigHashAnnotation = "json-server.example.com/config-hash"
+
+	// InstanceLabel is set on snapshot ConfigMaps to the JsonServer name.
+	InstanceLabel = "json-server.example.com/instance"
+
+	// DataSourceLabel set to "true" on a Secret lets JsonServers in its
+	// namespace serve it through spec.dataFrom.secretKeyRef. Other Secrets
+	// cannot be read, so creating a JsonServer does not expose them.
+	DataSourceLabel = "json-server.example.com/data-source"
+
+	// Finalizer holds a deleted JsonServer until spec.deletionPolicy has
+	// been applied.
+	Finalizer = "json-server.example.com/finalizer"
+
+	// ProtectedAnnotation set to "true" makes the webhook reject deletes of
+	// the JsonServer, like spec.deletionProtection.
+	ProtectedAnnotation = "json-server.example.com/protected"
+
+	// DeletionProtectionLabel set to DeletionProtectionRequired on a
+	// namespace makes the webhook reject deletes of every JsonServer in it.
+	DeletionProtectionLabel = "json-server.example.com/deletion-protection"
+
+	// DeletionProtectionRequired is the value of DeletionProtectionLabel
+	// that enables the namespace policy.
+	DeletionProtectionRequired = "required"
+)
+
+// Size limits for the data rendered into the instance ConfigMap.
+const (
+	// MaxConfigMapBytes is the most data a ConfigMap can hold.
+	MaxConfigMapBytes = 1024 * 1024
+
+	// CompressThresholdBytes is the jsonConfig size above which db.json is
+	// stored gzip-compressed in the ConfigMap and unpacked when pods start.
+	CompressThresholdBytes = 512 * 1024
+)
+
+// Summary states reported in JsonServerStatus.State.
+const (
+	StateSynced = "Synced"
+	StateError  = "Error"
+)
+
+// Condition types reported in JsonServerStatus.Conditions.
+const (
+	// ConditionReady is True when all other conditions are True.
+	ConditionReady = "Ready"
+	// ConditionConfigValid is True when spec.jsonConfig is valid and rendered into the ConfigMap.
+	ConditionConfigValid = "ConfigValid"
+	// ConditionDeploymentAvailable is True when every desired replica is updated and available.
+	ConditionDeploymentAvailable = "DeploymentAvailable"
+	// ConditionServiceReady is True when the Service exposing the instance exists.
+	ConditionServiceReady = "ServiceReady"
+	// ConditionStorageReady is True when the PersistentVolumeClaim for spec.storage exists.
+	// It is only reported when spec.storage is set.
+	ConditionStorageReady = "StorageReady"
+	// ConditionIngressReady is True when the Ingress or HTTPRoute for spec.ingress exists.
+	// It is only reported when spec.ingress is set.
+	ConditionIngressReady = "IngressReady"
+	// ConditionSchemaValid is True when every item matches spec.schemas. It
+	// is only reported when schemas are set and does not affect Ready.
+	ConditionSchemaValid = "SchemaValid"
+)
+
+// Condition reasons reported in JsonServerStatus.Conditions.
+const (
+	ReasonReconciling     = "Reconciling"
+	ReasonValid           = "Valid"
+	ReasonInvalidConfig   = "InvalidConfig"
+	ReasonReconcileFailed = "ReconcileFailed"
+	ReasonProgressing     = "Progressing"
+	ReasonAvailable       = "Available"
+	ReasonSchemaMismatch  = "SchemaMismatch"
+)
+
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:subresource:scale:specpath=.spec.replicas,statuspath=.status.replicas
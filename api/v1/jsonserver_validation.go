@@ -0,0 +1,197 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// maxDBErrors limits the errors ValidateDB reports, so a broken data set
+// does not produce a message larger than the object itself.
+const maxDBErrors = 20
+
+// ValidateDB checks that data has the shape json-server serves: an object
+// whose values are arrays of objects (collections) or objects (singular
+// resources). Ids must be unique within a collection, and foreign keys such
+// as postId must reference an item of the matching collection, e.g. posts,
+// when that collection exists. Only the first maxDBErrors errors are
+// returned.
+func ValidateDB(data []byte, server *ServerSpec, fldPath *field.Path) field.ErrorList {
+	idField, fkSuffix := DefaultIDField, DefaultForeignKeySuffix
+	if server != nil && server.IDField != "" {
+		idField = server.IDField
+	}
+	if server != nil && server.ForeignKeySuffix != "" {
+		fkSuffix = server.ForeignKeySuffix
+	}
+
+	var errs field.ErrorList
+	var db map[string]any
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&db); err != nil || db == nil {
+		return append(errs, field.Invalid(fldPath, "<invalid json>", "must be a json object"))
+	}
+
+	names := make([]string, 0, len(db))
+	for name := range db {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// Collect the items and ids of every collection.
+	collections := map[string][]map[string]any{}
+	ids := map[string]map[string]bool{}
+	for _, name := range names {
+		colPath := fldPath.Child(name)
+
+		switch value := db[name].(type) {
+		case map[string]any:
+			// A singular resource has no items.
+		case []any:
+			ids[name] = map[string]bool{}
+			for i, elem := range value {
+				item, ok := elem.(map[string]any)
+				if !ok {
+					errs = append(errs, field.Invalid(colPath.Index(i), jsonType(elem), "must be a json object"))
+					continue
+				}
+				collections[name] = append(collections[name], item)
+
+				raw, ok := item[idField]
+				if !ok {
+					// json-server generates missing ids.
+					continue
+				}
+				id, ok := idKey(raw)
+				switch {
+				case !ok:
+					errs = append(errs, field.Invalid(colPath.Index(i).Child(idField), jsonType(raw),
+						"must be a string or number"))
+				case ids[name][id]:
+					errs = append(errs, field.Duplicate(colPath.Index(i).Child(idField), plainValue(raw)))
+				default:
+					ids[name][id] = true
+				}
+			}
+		default:
+			errs = append(errs, field.Invalid(colPath, jsonType(value),
+				"must be an array of objects or an object"))
+		}
+	}
+
+	// Check the foreign keys against the collected ids.
+	for _, name := range names {
+		for i, item := range collections[name] {
+			keys := make([]string, 0, len(item))
+			for key := range item {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+
+			for _, key := range keys {
+				ref := strings.TrimSuffix(key, fkSuffix)
+				if ref == key || ref == "" || key == idField || item[key] == nil {
+					continue
+				}
+				target, ok := ReferencedCollection(ref, func(name string) bool {
+					_, ok := ids[name]
+					return ok
+				})
+				if !ok {
+					// Not a reference to a known collection, e.g. externalId.
+					continue
+				}
+				if id, ok := idKey(item[key]); !ok || !ids[target][id] {
+					errs = append(errs, field.NotFound(fldPath.Child(name).Index(i).Child(key), plainValue(item[key])))
+				}
+			}
+		}
+	}
+
+	if len(errs) > maxDBErrors {
+		errs = errs[:maxDBErrors]
+	}
+	return errs
+}
+
+// idKey returns the comparable form of an id. json-server matches ids as
+// strings, so 1 and "1" are the same id.
+func idKey(value any) (string, bool) {
+	switch id := value.(type) {
+	case json.Number:
+		return id.String(), true
+	case string:
+		return id, true
+	}
+	return "", false
+}
+
+// plainValue turns a decoded number back into an int64 or float64, so
+// errors print it as written.
+func plainValue(value any) any {
+	n, ok := value.(json.Number)
+	if !ok {
+		return value
+	}
+	if i, err := n.Int64(); err == nil {
+		return i
+	}
+	if f, err := n.Float64(); err == nil {
+		return f
+	}
+	return n.String()
+}
+
+// jsonType names the JSON type of a decoded value for error messages,
+// which would otherwise print whole arrays and objects.
+func jsonType(value any) string {
+	switch value.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case json.Number:
+		return "number"
+	case bool:
+		return "boolean"
+	}
+	return "null"
+}
+
+// ReferencedCollection finds the collection a foreign key refers to, e.g.
+// posts for post, categories for category, or staff for staff. exists
+// reports whether a collection is defined.
+func ReferencedCollection(ref string, exists func(name string) bool) (string, bool) {
+	candidates := []string{ref + "s", ref + "es", ref}
+	if base, ok := strings.CutSuffix(ref, "y"); ok {
+		candidates = append(candidates, base+"ies")
+	}
+	for _, name := range candidates {
+		if exists(name) {
+			return name, true
+		}
+	}
+	return "", false
+}
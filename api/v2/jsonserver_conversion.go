@@ -0,0 +1,219 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"reflect"
+	"strings"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	examplev1 "github.com/BlueTurtle-bytes/json-server/api/v1"
+)
+
+// maxFormattedTextBytes bounds the v1 JSON text kept in spec.v1Text only
+// for its formatting, since it doubles the size of the stored data. Larger
+// text reads back indented.
+const maxFormattedTextBytes = 256 * 1024
+
+// ConvertTo converts this JsonServer to the hub version (v1). Structured
+// data becomes the v1 text it was converted from while it still holds the
+// same data, and indented JSON text with sorted keys otherwise.
+func (src *JsonServer) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*examplev1.JsonServer)
+	in := src.DeepCopy()
+
+	texts := in.Spec.V1Text
+
+	dst.ObjectMeta = in.ObjectMeta
+	dst.Status = in.Status
+	dst.Spec = examplev1.JsonServerSpec{
+		Replicas:           in.Spec.Replicas,
+		DataFrom:           in.Spec.DataFrom,
+		DataFormat:         in.Spec.DataFormat,
+		OpenAPI:            in.Spec.OpenAPI,
+		Generate:           in.Spec.Generate,
+		SchemaEnforcement:  in.Spec.SchemaEnforcement,
+		Port:               in.Spec.Port,
+		Image:              in.Spec.Image,
+		Server:             in.Spec.Server,
+		Storage:            in.Spec.Storage,
+		Consistency:        in.Spec.Consistency,
+		Service:            in.Spec.Service,
+		Ingress:            in.Spec.Ingress,
+		DeletionPolicy:     in.Spec.DeletionPolicy,
+		DeletionProtection: in.Spec.DeletionProtection,
+	}
+
+	var err error
+	if dst.Spec.JsonConfig, err = toText(in.Spec.Data, "spec.jsonConfig", texts); err != nil {
+		return err
+	}
+	for i, c := range in.Spec.Collections {
+		collection := examplev1.CollectionSource{Name: c.Name, ConfigMapKeyRef: c.ConfigMapKeyRef, Format: c.Format}
+		if collection.Inline, err = toText(c.Data, fmt.Sprintf("spec.collections[%d].inline", i), texts); err != nil {
+			return err
+		}
+		dst.Spec.Collections = append(dst.Spec.Collections, collection)
+	}
+	if in.Spec.Schemas != nil {
+		dst.Spec.Schemas = make(map[string]examplev1.SchemaSource, len(in.Spec.Schemas))
+	}
+	for name, s := range in.Spec.Schemas {
+		schema := examplev1.SchemaSource{ConfigMapKeyRef: s.ConfigMapKeyRef}
+		if schema.Inline, err = toText(s.Schema, fmt.Sprintf("spec.schemas[%s].inline", name), texts); err != nil {
+			return err
+		}
+		dst.Spec.Schemas[name] = schema
+	}
+	return nil
+}
+
+// ConvertFrom converts from the hub version (v1) to this version. JSON
+// text becomes structured data. Text that is not JSON, or JSON that
+// ConvertTo would not render the same, such as compact JSON, is kept in
+// spec.v1Text so v1 clients read back what they wrote.
+func (dst *JsonServer) ConvertFrom(srcRaw conversion.Hub) error {
+	in := srcRaw.(*examplev1.JsonServer).DeepCopy()
+
+	dst.ObjectMeta = in.ObjectMeta
+	dst.Status = in.Status
+	dst.Spec = JsonServerSpec{
+		Replicas:           in.Spec.Replicas,
+		DataFrom:           in.Spec.DataFrom,
+		DataFormat:         in.Spec.DataFormat,
+		OpenAPI:            in.Spec.OpenAPI,
+		Generate:           in.Spec.Generate,
+		SchemaEnforcement:  in.Spec.SchemaEnforcement,
+		Port:               in.Spec.Port,
+		Image:              in.Spec.Image,
+		Server:             in.Spec.Server,
+		Storage:            in.Spec.Storage,
+		Consistency:        in.Spec.Consistency,
+		Service:            in.Spec.Service,
+		Ingress:            in.Spec.Ingress,
+		DeletionPolicy:     in.Spec.DeletionPolicy,
+		DeletionProtection: in.Spec.DeletionProtection,
+	}
+
+	texts, formatted := map[string]string{}, map[string]string{}
+	dst.Spec.Data = toData(in.Spec.JsonConfig, "spec.jsonConfig", texts, formatted)
+	for i, c := range in.Spec.Collections {
+		dst.Spec.Collections = append(dst.Spec.Collections, CollectionSource{
+			Name:            c.Name,
+			Data:            toData(c.Inline, fmt.Sprintf("spec.collections[%d].inline", i), texts, formatted),
+			ConfigMapKeyRef: c.ConfigMapKeyRef,
+			Format:          c.Format,
+		})
+	}
+	if in.Spec.Schemas != nil {
+		dst.Spec.Schemas = make(map[string]SchemaSource, len(in.Spec.Schemas))
+	}
+	for name, s := range in.Spec.Schemas {
+		dst.Spec.Schemas[name] = SchemaSource{
+			Schema:          toData(s.Inline, fmt.Sprintf("spec.schemas[%s].inline", name), texts, formatted),
+			ConfigMapKeyRef: s.ConfigMapKeyRef,
+		}
+	}
+
+	size := 0
+	for _, text := range formatted {
+		size += len(text)
+	}
+	if size <= maxFormattedTextBytes {
+		maps.Copy(texts, formatted)
+	}
+	if len(texts) > 0 {
+		dst.Spec.V1Text = texts
+	}
+	return nil
+}
+
+// toData parses v1 JSON text. Text that is not JSON, or is null, cannot be
+// stored as data and is recorded in texts under path. JSON text that toText
+// would render differently is recorded in formatted.
+func toData(text, path string, texts, formatted map[string]string) *apiextensionsv1.JSON {
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" {
+		return nil
+	}
+	if trimmed == "null" || !json.Valid([]byte(trimmed)) {
+		texts[path] = text
+		return nil
+	}
+
+	var compact bytes.Buffer
+	_ = json.Compact(&compact, []byte(trimmed))
+	data := &apiextensionsv1.JSON{Raw: compact.Bytes()}
+	if indented, _ := toText(data, path, nil); indented != text {
+		formatted[path] = text
+	}
+	return data
+}
+
+// toText returns the text recorded under path when there is no data or
+// the text holds the same data, so formatting and key order survive the
+// round trip. Other data is rendered as indented JSON text with sorted keys.
+func toText(data *apiextensionsv1.JSON, path string, texts map[string]string) (string, error) {
+	text, ok := texts[path]
+	if data == nil || len(data.Raw) == 0 {
+		return text, nil
+	}
+	if ok && sameJSON([]byte(text), data.Raw) {
+		return text, nil
+	}
+
+	indented, err := indentJSON(data.Raw)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", path, err)
+	}
+	return indented, nil
+}
+
+// indentJSON renders raw indented with sorted keys, the way the API server
+// stores structured data, keeping numbers as written.
+func indentJSON(raw []byte) (string, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return "", err
+	}
+	var out bytes.Buffer
+	enc := json.NewEncoder(&out)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(out.String(), "\n"), nil
+}
+
+// sameJSON reports whether a and b hold the same JSON value, regardless of
+// formatting, key order and number notation.
+func sameJSON(a, b []byte) bool {
+	var va, vb any
+	if json.Unmarshal(a, &va) != nil || json.Unmarshal(b, &vb) != nil {
+		return false
+	}
+	return reflect.DeepEqual(va, vb)
+}
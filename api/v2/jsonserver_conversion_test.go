@@ -0,0 +1,162 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/conversion"
+
+	examplev1 "github.com/BlueTurtle-bytes/json-server/api/v1"
+)
+
+var _ = Describe("JsonServer conversion", func() {
+	newV1 := func() *examplev1.JsonServer {
+		replicas := int32(2)
+		return &examplev1.JsonServer{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "app-convert",
+				Namespace:   "default",
+				Annotations: map[string]string{examplev1.ProtectedAnnotation: "true"},
+			},
+			Spec: examplev1.JsonServerSpec{
+				Replicas:   &replicas,
+				JsonConfig: "{\n  \"people\": [\n    {\n      \"id\": 1\n    }\n  ]\n}",
+				Collections: []examplev1.CollectionSource{
+					{Name: "posts", Inline: "[]"},
+					{Name: "tags", ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "fixtures"},
+						Key:                  "tags.json",
+					}},
+				},
+				Schemas: map[string]examplev1.SchemaSource{
+					"people": {Inline: "{\n  \"type\": \"object\"\n}"},
+				},
+				DeletionPolicy: examplev1.DeletionPolicySnapshot,
+			},
+			Status: examplev1.JsonServerStatus{State: examplev1.StateSynced},
+		}
+	}
+
+	It("should serve v1 through the v2 storage version", func() {
+		scheme := runtime.NewScheme()
+		Expect(examplev1.AddToScheme(scheme)).To(Succeed())
+		Expect(AddToScheme(scheme)).To(Succeed())
+		Expect(conversion.IsConvertible(scheme, &examplev1.JsonServer{})).To(BeTrue())
+	})
+
+	It("should store JSON text as structured data", func() {
+		v2 := &JsonServer{}
+		Expect(v2.ConvertFrom(newV1())).To(Succeed())
+
+		Expect(string(v2.Spec.Data.Raw)).To(Equal(`{"people":[{"id":1}]}`))
+		Expect(string(v2.Spec.Collections[0].Data.Raw)).To(Equal(`[]`))
+		Expect(v2.Spec.Collections[1].Data).To(BeNil())
+		Expect(v2.Spec.Collections[1].ConfigMapKeyRef.Key).To(Equal("tags.json"))
+		Expect(string(v2.Spec.Schemas["people"].Schema.Raw)).To(Equal(`{"type":"object"}`))
+		Expect(*v2.Spec.Replicas).To(Equal(int32(2)))
+		Expect(v2.Spec.DeletionPolicy).To(Equal(examplev1.DeletionPolicySnapshot))
+		Expect(v2.Status.State).To(Equal(examplev1.StateSynced))
+	})
+
+	It("should round-trip v1 and v2", func() {
+		v1 := newV1()
+		v2 := &JsonServer{}
+		Expect(v2.ConvertFrom(v1)).To(Succeed())
+		back := &examplev1.JsonServer{}
+		Expect(v2.ConvertTo(back)).To(Succeed())
+		Expect(back).To(Equal(v1))
+
+		again := &JsonServer{}
+		Expect(again.ConvertFrom(back)).To(Succeed())
+		Expect(again).To(Equal(v2))
+	})
+
+	DescribeTable("should keep the exact v1 text of unchanged data",
+		func(text string, kept bool) {
+			v1 := newV1()
+			v1.Spec.JsonConfig = text
+			v2 := &JsonServer{}
+			Expect(v2.ConvertFrom(v1)).To(Succeed())
+			if kept {
+				Expect(v2.Spec.V1Text).To(HaveKeyWithValue("spec.jsonConfig", text))
+			} else {
+				Expect(v2.Spec.V1Text).To(BeEmpty())
+			}
+
+			back := &examplev1.JsonServer{}
+			Expect(v2.ConvertTo(back)).To(Succeed())
+			Expect(back.Spec.JsonConfig).To(Equal(text))
+		},
+		Entry("indented", "{\n  \"people\": []\n}", false),
+		Entry("compact", `{"people":[{"id":1,"name":"a"}]}`, true),
+		Entry("unsorted keys", "{\n  \"posts\": [],\n  \"comments\": []\n}", true),
+		Entry("other indentation", "{\n    \"people\": [ 1, 2 ]\n}\n", true),
+		Entry("number notation", `{"people":[{"id":1.0e0}]}`, true),
+		Entry("markup", "{\n  \"pages\": [\n    \"<a href=\\\"/?a=1&b=2\\\">\"\n  ]\n}", false),
+	)
+
+	It("should indent data changed through v2", func() {
+		v1 := newV1()
+		v1.Spec.JsonConfig = `{"people":[]}`
+		v2 := &JsonServer{}
+		Expect(v2.ConvertFrom(v1)).To(Succeed())
+
+		v2.Spec.Data = &apiextensionsv1.JSON{Raw: []byte(`{"people":[],"posts":[]}`)}
+		back := &examplev1.JsonServer{}
+		Expect(v2.ConvertTo(back)).To(Succeed())
+		Expect(back.Spec.JsonConfig).To(Equal("{\n  \"people\": [],\n  \"posts\": []\n}"))
+	})
+
+	It("should not keep the formatting of large text", func() {
+		v1 := newV1()
+		v1.Spec.JsonConfig = `{"people":["` + strings.Repeat("a", maxFormattedTextBytes) + `"]}`
+		v2 := &JsonServer{}
+		Expect(v2.ConvertFrom(v1)).To(Succeed())
+		Expect(v2.Spec.V1Text).To(BeEmpty())
+
+		back := &examplev1.JsonServer{}
+		Expect(v2.ConvertTo(back)).To(Succeed())
+		Expect(back.Spec.JsonConfig).To(HavePrefix("{\n  \"people\": [\n"))
+	})
+
+	It("should keep v1 text that is not JSON", func() {
+		v1 := newV1()
+		v1.Spec.JsonConfig = `{ invalid json }`
+		v2 := &JsonServer{}
+		Expect(v2.ConvertFrom(v1)).To(Succeed())
+		Expect(v2.Spec.Data).To(BeNil())
+		Expect(v2.Spec.V1Text).To(Equal(map[string]string{"spec.jsonConfig": `{ invalid json }`}))
+
+		back := &examplev1.JsonServer{}
+		Expect(v2.ConvertTo(back)).To(Succeed())
+		Expect(back.Spec.JsonConfig).To(Equal(`{ invalid json }`))
+		Expect(back.Annotations).To(Equal(v1.Annotations))
+
+		By("preferring data set through v2 over the kept text")
+		v2.Spec.Data = &apiextensionsv1.JSON{Raw: []byte(`{}`)}
+		Expect(v2.ConvertTo(back)).To(Succeed())
+		Expect(back.Spec.JsonConfig).To(Equal(`{}`))
+	})
+})
@@ -0,0 +1,194 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	examplev1 "github.com/BlueTurtle-bytes/json-server/api/v1"
+)
+
+// JsonServerSpec defines the desired state of JsonServer. It is the v1
+// spec with db.json, inline collections and inline schemas held as
+// structured data instead of JSON strings.
+type JsonServerSpec struct {
+	// Replicas is the number of json-server pods. Defaults to 1.
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// Data is the inline db.json. At most one of data, dataFrom and openAPI
+	// may be set, and one of them or collections is required.
+	// +optional
+	Data *apiextensionsv1.JSON `json:"data,omitempty"`
+
+	// DataFrom reads db.json from a key of a ConfigMap or Secret in the same
+	// namespace. Changes to the referenced object roll the pods.
+	// +optional
+	DataFrom *examplev1.DataSource `json:"dataFrom,omitempty"`
+
+	// DataFormat is the format of the data read through dataFrom. Other
+	// formats are converted to JSON before they are served. Defaults to json.
+	// +optional
+	DataFormat examplev1.DataFormat `json:"dataFormat,omitempty"`
+
+	// OpenAPI generates db.json, and routes that mirror the API paths, from
+	// an OpenAPI 3 document in a ConfigMap.
+	// +optional
+	OpenAPI *examplev1.OpenAPISource `json:"openAPI,omitempty"`
+
+	// Collections adds top-level collections to db.json, each from its own
+	// source. Names must be unique and must not repeat a key of data,
+	// dataFrom or openAPI.
+	// +optional
+	Collections []CollectionSource `json:"collections,omitempty"`
+
+	// Generate adds collections of synthetic items to db.json. Names share
+	// the rules of collections.
+	// +optional
+	Generate []examplev1.GenerateSpec `json:"generate,omitempty"`
+
+	// Schemas maps a collection name to the JSON Schema every item of the
+	// collection must match. A singular (object) resource is matched as a
+	// whole.
+	// +optional
+	Schemas map[string]SchemaSource `json:"schemas,omitempty"`
+
+	// SchemaEnforcement decides what happens to data that does not match
+	// spec.schemas. Defaults to Deny when schemas are set.
+	// +optional
+	SchemaEnforcement examplev1.SchemaEnforcement `json:"schemaEnforcement,omitempty"`
+
+	// Port is the port json-server listens on inside the pod. Defaults to 3000.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	Port int32 `json:"port,omitempty"`
+
+	// Image overrides the operator-wide default json-server image.
+	// +optional
+	Image *examplev1.ImageSpec `json:"image,omitempty"`
+
+	// Server configures json-server command-line options.
+	// +optional
+	Server *examplev1.ServerSpec `json:"server,omitempty"`
+
+	// Storage keeps db.json on a PersistentVolumeClaim so writes made through
+	// the REST API survive pod restarts. When unset, data is served read-only
+	// from the ConfigMap and lost on restart.
+	// +optional
+	Storage *examplev1.StorageSpec `json:"storage,omitempty"`
+
+	// Consistency decides how data stays consistent across replicas.
+	// +optional
+	Consistency *examplev1.ConsistencySpec `json:"consistency,omitempty"`
+
+	// Service configures the Service that exposes the instance.
+	// +optional
+	Service *examplev1.ServiceSpec `json:"service,omitempty"`
+
+	// Ingress exposes the instance outside the cluster through an Ingress or,
+	// when a Gateway is referenced, a Gateway API HTTPRoute.
+	// +optional
+	Ingress *examplev1.IngressSpec `json:"ingress,omitempty"`
+
+	// DeletionPolicy decides what happens to the data and child resources
+	// when the JsonServer is deleted. Defaults to Delete.
+	// +optional
+	DeletionPolicy examplev1.DeletionPolicy `json:"deletionPolicy,omitempty"`
+
+	// DeletionProtection makes the webhook reject deletes of the JsonServer.
+	// The protected annotation has the same effect.
+	// +optional
+	DeletionProtection bool `json:"deletionProtection,omitempty"`
+
+	// V1Text keeps v1 text by field path, e.g. spec.jsonConfig, so v1
+	// clients read back what they wrote: text that is not JSON, and JSON
+	// formatted otherwise than indented with sorted keys. It is maintained by
+	// the conversion from v1; text whose data has since changed is ignored.
+	// +optional
+	V1Text map[string]string `json:"v1Text,omitempty"`
+}
+
+// CollectionSource is one top-level collection of db.json. Exactly one of
+// data and configMapKeyRef must be set.
+type CollectionSource struct {
+	// Name is the key of the collection in db.json and its REST path, e.g. users.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Data is the collection, usually an array of items.
+	// +optional
+	Data *apiextensionsv1.JSON `json:"data,omitempty"`
+
+	// ConfigMapKeyRef selects a ConfigMap key holding the collection.
+	// +optional
+	ConfigMapKeyRef *corev1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+
+	// Format is the format of the collection read through configMapKeyRef.
+	// Defaults to json.
+	// +optional
+	Format examplev1.CollectionFormat `json:"format,omitempty"`
+}
+
+// SchemaSource is a JSON Schema. Exactly one of schema and configMapKeyRef
+// must be set.
+type SchemaSource struct {
+	// Schema is the JSON Schema.
+	// +optional
+	Schema *apiextensionsv1.JSON `json:"schema,omitempty"`
+
+	// ConfigMapKeyRef selects a ConfigMap key holding the JSON Schema.
+	// +optional
+	ConfigMapKeyRef *corev1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:subresource:scale:specpath=.spec.replicas,statuspath=.status.replicas
+// +kubebuilder:storageversion
+
+// JsonServer is the Schema for the jsonservers API
+type JsonServer struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// metadata is a standard object metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitzero"`
+
+	// spec defines the desired state of JsonServer
+	// +required
+	Spec JsonServerSpec `json:"spec"`
+
+	// status defines the observed state of JsonServer
+	// +optional
+	Status examplev1.JsonServerStatus `json:"status,omitzero"`
+}
+
+// +kubebuilder:object:root=true
+
+// JsonServerList contains a list of JsonServer
+type JsonServerList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitzero"`
+	Items           []JsonServer `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&JsonServer{}, &JsonServerList{})
+}
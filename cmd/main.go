@@ -20,12 +20,14 @@ import (
 	"crypto/tls"
 	"flag"
 	"os"
+	"strings"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -36,6 +38,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	examplev1 "github.com/BlueTurtle-bytes/json-server/api/v1"
+	examplev2 "github.com/BlueTurtle-bytes/json-server/api/v2"
 	"github.com/BlueTurtle-bytes/json-server/internal/controller"
 	webhookv1 "github.com/BlueTurtle-bytes/json-server/internal/webhook/v1"
 	// +kubebuilder:scaffold:imports
@@ -50,6 +53,7 @@ func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 
 	utilruntime.Must(examplev1.AddToScheme(scheme))
+	utilruntime.Must(examplev2.AddToScheme(scheme))
 	// +kubebuilder:scaffold:scheme
 }
 
@@ -62,6 +66,8 @@ func main() {
 	var probeAddr string
 	var secureMetrics bool
 	var enableHTTP2 bool
+	var defaultImage string
+	var namingPolicyFile, namingPolicyConfigMap string
 	var tlsOpts []func(*tls.Config)
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
@@ -80,6 +86,14 @@ func main() {
 	flag.StringVar(&metricsCertKey, "metrics-cert-key", "tls.key", "The name of the metrics server key file.")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.StringVar(&defaultImage, "default-image", examplev1.DefaultImage,
+		"The json-server image used for JsonServers that do not set spec.image. "+
+			"Point this at a mirrored registry for air-gapped clusters.")
+	flag.StringVar(&namingPolicyFile, "naming-policy-file", "",
+		"A YAML file with the naming policy for new JsonServers. The default policy requires the app- prefix.")
+	flag.StringVar(&namingPolicyConfigMap, "naming-policy-configmap", "",
+		"A ConfigMap, as namespace/name, whose "+webhookv1.NamingPolicyKey+" key holds the naming policy. "+
+			"It is read on every create and takes precedence over --naming-policy-file while it exists.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -180,15 +194,43 @@ func main() {
 	}
 
 	if err := (&controller.JsonServerReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:       mgr.GetClient(),
+		Scheme:       mgr.GetScheme(),
+		DefaultImage: defaultImage,
+		Recorder:     mgr.GetEventRecorder("jsonserver-controller"),
+		APIReader:    mgr.GetAPIReader(),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "JsonServer")
 		os.Exit(1)
 	}
+	// The conversion webhook is not optional: without it the v2 storage
+	// version cannot be read, so ENABLE_WEBHOOKS only disables admission.
+	if err := webhookv1.SetupJsonServerConversionWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create conversion webhook", "webhook", "JsonServer")
+		os.Exit(1)
+	}
 	// nolint:goconst
 	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
-		if err := webhookv1.SetupJsonServerWebhookWithManager(mgr); err != nil {
+		webhookOpts := webhookv1.Options{}
+		if namingPolicyFile != "" {
+			data, err := os.ReadFile(namingPolicyFile)
+			if err == nil {
+				webhookOpts.NamingPolicy, err = webhookv1.ParseNamingPolicy(data, "file "+namingPolicyFile)
+			}
+			if err != nil {
+				setupLog.Error(err, "unable to load naming policy", "file", namingPolicyFile)
+				os.Exit(1)
+			}
+		}
+		if namingPolicyConfigMap != "" {
+			namespace, name, ok := strings.Cut(namingPolicyConfigMap, "/")
+			if !ok || namespace == "" || name == "" {
+				setupLog.Error(nil, "--naming-policy-configmap must be namespace/name", "value", namingPolicyConfigMap)
+				os.Exit(1)
+			}
+			webhookOpts.NamingPolicyConfigMap = &types.NamespacedName{Namespace: namespace, Name: name}
+		}
+		if err := webhookv1.SetupJsonServerWebhookWithManager(mgr, webhookOpts); err != nil {
 			setupLog.Error(err, "unable to create webhook", "webhook", "JsonServer")
 			os.Exit(1)
 		}
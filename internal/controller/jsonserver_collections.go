@@ -0,0 +1,241 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	examplev1 "github.com/BlueTurtle-bytes/json-server/api/v1"
+	"github.com/BlueTurtle-bytes/json-server/internal/dataformat"
+	"github.com/BlueTurtle-bytes/json-server/internal/generate"
+)
+
+// errInvalidCollection is wrapped by errors for collections that cannot be
+// merged into db.json. It is not retried; fixing the spec or the referenced
+// ConfigMap triggers a new reconcile.
+var errInvalidCollection = errors.New("invalid collection")
+
+// maxRowErrors caps the row errors reported per collection, so a broken
+// export cannot grow the status without bound.
+const maxRowErrors = 10
+
+// -------------------- Collections --------------------
+
+// composeCollections merges spec.collections and spec.generate into
+// spec.jsonConfig of the in-memory object and reports every top-level
+// collection of the result in status. Without them jsonConfig is served
+// unchanged.
+func (r *JsonServerReconciler) composeCollections(ctx context.Context, js *examplev1.JsonServer) error {
+	db := map[string]json.RawMessage{}
+	sources := map[string]string{}
+	rowErrs := map[string][]examplev1.RowError{}
+
+	// Only a JSON object has collections to report. json.Unmarshal fails
+	// for other values except null, which leaves db nil.
+	if err := json.Unmarshal([]byte(js.Spec.JsonConfig), &db); err != nil || db == nil {
+		if composed(js) {
+			return fmt.Errorf("Error: %s must be a json object to add collections: %w",
+				dataSourceName(js), errInvalidCollection)
+		}
+		js.Status.Collections = nil
+		return nil
+	}
+	for name := range db {
+		sources[name] = dataSourceName(js)
+	}
+
+	for _, c := range js.Spec.Collections {
+		if source, ok := sources[c.Name]; ok {
+			return fmt.Errorf("Error: collection %q is already defined by %s: %w",
+				c.Name, source, errInvalidCollection)
+		}
+
+		text, err := r.readCollection(ctx, js.Namespace, c)
+		if err != nil {
+			return err
+		}
+		data, skipped, err := dataformat.DecodeCollection([]byte(text), c.Format)
+		if err != nil {
+			return fmt.Errorf("Error: collection %q from %s is not valid %s: %v: %w",
+				c.Name, collectionSourceName(c), cmp.Or(c.Format, examplev1.CollectionFormatJSON), err, errInvalidCollection)
+		}
+		if !dataformat.IsCollection(data) {
+			return fmt.Errorf("Error: collection %q from %s is not a json array or object: %w",
+				c.Name, collectionSourceName(c), errInvalidCollection)
+		}
+		db[c.Name] = json.RawMessage(data)
+		sources[c.Name] = collectionSourceName(c)
+		rowErrs[c.Name] = skipped
+	}
+
+	if err := generateCollections(js, db, sources); err != nil {
+		return err
+	}
+
+	if composed(js) {
+		merged, err := json.Marshal(db)
+		if err != nil {
+			return err
+		}
+		js.Spec.JsonConfig = string(merged)
+	}
+
+	js.Status.Collections = collectionStatuses(db, sources, rowErrs)
+	return nil
+}
+
+// generateCollections renders spec.generate into db. Templates may
+// reference any collection, including those generated after them, whose
+// ids are known up front.
+func generateCollections(js *examplev1.JsonServer, db map[string]json.RawMessage, sources map[string]string) error {
+	if len(js.Spec.Generate) == 0 {
+		return nil
+	}
+
+	idField := examplev1.DefaultIDField
+	if js.Spec.Server != nil && js.Spec.Server.IDField != "" {
+		idField = js.Spec.Server.IDField
+	}
+
+	ids := map[string][]any{}
+	for _, spec := range js.Spec.Generate {
+		list := make([]any, spec.Count)
+		for i := range list {
+			list[i] = i + 1
+		}
+		ids[spec.Name] = list
+	}
+	for _, spec := range js.Spec.Generate {
+		for _, template := range spec.Fields {
+			// Invalid templates are reported by generate.Items.
+			t, err := generate.Parse(template)
+			if err != nil {
+				continue
+			}
+			for _, ref := range t.Refs() {
+				if _, ok := ids[ref]; !ok && db[ref] != nil {
+					ids[ref] = collectionIDs(db[ref], idField)
+				}
+			}
+		}
+	}
+
+	for _, spec := range js.Spec.Generate {
+		if source, ok := sources[spec.Name]; ok {
+			return fmt.Errorf("Error: collection %q is already defined by %s: %w",
+				spec.Name, source, errInvalidCollection)
+		}
+
+		items, err := generate.Items(spec, idField, ids)
+		if err != nil {
+			return fmt.Errorf("Error: generated collection %q: %v: %w", spec.Name, err, errInvalidCollection)
+		}
+		data, err := json.Marshal(items)
+		if err != nil {
+			return err
+		}
+		db[spec.Name] = data
+		sources[spec.Name] = "generated"
+	}
+	return nil
+}
+
+// readCollection returns the text of a collection in its format. A missing
+// optional key reads as an empty collection.
+func (r *JsonServerReconciler) readCollection(
+	ctx context.Context,
+	namespace string,
+	c examplev1.CollectionSource,
+) (string, error) {
+
+	if c.ConfigMapKeyRef == nil {
+		return c.Inline, nil
+	}
+	fallback := emptyCollection
+	if c.Format == examplev1.CollectionFormatCSV || c.Format == examplev1.CollectionFormatNDJSON {
+		// An empty CSV or NDJSON document is an empty collection.
+		fallback = ""
+	}
+	return r.readDataSource(ctx, namespace,
+		&examplev1.DataSource{ConfigMapKeyRef: c.ConfigMapKeyRef}, fallback)
+}
+
+// -------------------- Helpers --------------------
+
+// composed reports whether db.json is composed from more than one source.
+func composed(js *examplev1.JsonServer) bool {
+	return len(js.Spec.Collections) > 0 || len(js.Spec.Generate) > 0
+}
+
+// collectionIDs returns the ids of the items of a collection.
+func collectionIDs(data json.RawMessage, idField string) []any {
+	var items []map[string]any
+	if json.Unmarshal(data, &items) != nil {
+		return nil
+	}
+	ids := make([]any, 0, len(items))
+	for _, item := range items {
+		if id, ok := item[idField]; ok && id != nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// collectionSourceName names where a collection comes from in status.
+func collectionSourceName(c examplev1.CollectionSource) string {
+	if ref := c.ConfigMapKeyRef; ref != nil {
+		return fmt.Sprintf("key %q of ConfigMap %s", ref.Key, ref.Name)
+	}
+	return "inline"
+}
+
+// collectionStatuses reports the collections of db, sorted by name. An
+// array counts its items, any other value counts as one. Skipped CSV and
+// NDJSON rows are reported up to maxRowErrors per collection.
+func collectionStatuses(
+	db map[string]json.RawMessage,
+	sources map[string]string,
+	rowErrs map[string][]examplev1.RowError,
+) []examplev1.CollectionStatus {
+	if len(db) == 0 {
+		return nil
+	}
+
+	statuses := make([]examplev1.CollectionStatus, 0, len(db))
+	for name, data := range db {
+		items := int32(1)
+		var list []json.RawMessage
+		if json.Unmarshal(data, &list) == nil {
+			items = int32(len(list))
+		}
+		statuses = append(statuses, examplev1.CollectionStatus{
+			Name:        name,
+			Source:      sources[name],
+			Items:       items,
+			SkippedRows: int32(len(rowErrs[name])),
+			RowErrors:   rowErrs[name][:min(len(rowErrs[name]), maxRowErrors)],
+		})
+	}
+	slices.SortFunc(statuses, func(a, b examplev1.CollectionStatus) int { return cmp.Compare(a.Name, b.Name) })
+	return statuses
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+
+	examplev1 "github.com/BlueTurtle-bytes/json-server/api/v1"
+)
+
+// dbGzipFile is the ConfigMap key of the compressed db.json. The seed init
+// container unpacks it to db.json on the data volume.
+const dbGzipFile = dbFile + ".gz"
+
+// compressDB reports whether db.json is too large to be stored as is.
+func compressDB(js *examplev1.JsonServer) bool {
+	return len(js.Spec.JsonConfig) > examplev1.CompressThresholdBytes
+}
+
+// renderConfigMap splits the rendered files into ConfigMap data and binary
+// data, compressing db.json when it is large. It fails when the result does
+// not fit into a ConfigMap.
+func renderConfigMap(js *examplev1.JsonServer) (map[string]string, map[string][]byte, error) {
+	data := configMapData(js)
+	var binaryData map[string][]byte
+
+	if compressDB(js) {
+		compressed, err := gzipBytes([]byte(data[dbFile]))
+		if err != nil {
+			return nil, nil, err
+		}
+		delete(data, dbFile)
+		binaryData = map[string][]byte{dbGzipFile: compressed}
+	}
+
+	size := 0
+	for key, value := range data {
+		size += len(key) + len(value)
+	}
+	for key, value := range binaryData {
+		size += len(key) + len(value)
+	}
+	if size > examplev1.MaxConfigMapBytes {
+		return nil, nil, fmt.Errorf(
+			"Error: rendered data is %d bytes, more than the %d bytes a ConfigMap can hold",
+			size, examplev1.MaxConfigMapBytes)
+	}
+
+	return data, binaryData, nil
+}
+
+// gzipBytes compresses data. The gzip header carries no timestamp, so equal
+// input gives equal output and the ConfigMap is not rewritten needlessly.
+func gzipBytes(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw, err := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
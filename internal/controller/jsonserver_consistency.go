@@ -0,0 +1,194 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	examplev1 "github.com/BlueTurtle-bytes/json-server/api/v1"
+)
+
+// forwardWritesMiddleware is the ConfigMap key of the middleware that
+// forwards writes to the writer in SingleWriter mode. The webhook reserves
+// the json-server- prefix for such generated files.
+const forwardWritesMiddleware = "json-server-forward-writes.js"
+
+// forwardWritesScript proxies every request that is not a read to
+// WRITER_URL before json-server handles it. json-server parses bodies in its
+// router, after CLI middlewares, so the request stream is still unread here.
+const forwardWritesScript = `const http = require('http');
+const writer = new URL(process.env.WRITER_URL);
+
+module.exports = (req, res, next) => {
+  if (['GET', 'HEAD', 'OPTIONS'].includes(req.method)) {
+    return next();
+  }
+  const upstream = http.request({
+    hostname: writer.hostname,
+    port: writer.port,
+    path: req.originalUrl,
+    method: req.method,
+    headers: req.headers,
+  }, (reply) => {
+    res.writeHead(reply.statusCode, reply.headers);
+    reply.pipe(res);
+  });
+  upstream.on('error', (err) => {
+    res.statusCode = 502;
+    res.end(String(err));
+  });
+  req.pipe(upstream);
+};
+`
+
+// syncScript copies /db from the writer over the local db.json. json-server
+// runs with --watch and reloads the file when it changes.
+const syncScript = `while true; do
+  if wget -q -O ` + dataDir + `/.db.json.sync "$WRITER_URL/db"; then
+    mv ` + dataDir + `/.db.json.sync ` + dataDir + `/` + dbFile + `
+  fi
+  sleep "$SYNC_INTERVAL"
+done
+`
+
+func consistencyMode(js *examplev1.JsonServer) examplev1.ConsistencyMode {
+	if js.Spec.Consistency == nil || js.Spec.Consistency.Mode == "" {
+		return examplev1.ConsistencyIndependent
+	}
+	return js.Spec.Consistency.Mode
+}
+
+func writerName(js *examplev1.JsonServer) string {
+	return js.Name + "-writer"
+}
+
+func writerURL(js *examplev1.JsonServer) string {
+	return fmt.Sprintf("http://%s.%s.svc:%d", writerName(js), js.Namespace, examplev1.DefaultServicePort)
+}
+
+// -------------------- Writer --------------------
+
+// reconcileWriter runs the writer Deployment and Service in SingleWriter
+// mode and removes them in every other mode. It returns nil when no writer
+// is wanted.
+func (r *JsonServerReconciler) reconcileWriter(
+	ctx context.Context,
+	js *examplev1.JsonServer,
+) (*appsv1.Deployment, error) {
+
+	if consistencyMode(js) != examplev1.ConsistencySingleWriter {
+		key := types.NamespacedName{Name: writerName(js), Namespace: js.Namespace}
+		for _, obj := range []client.Object{&appsv1.Deployment{}, &corev1.Service{}} {
+			if err := r.deleteOwned(ctx, js, key, obj); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	}
+
+	deploy, err := r.applyDeployment(ctx, js, r.desiredWriterDeployment(js))
+	if err != nil {
+		return nil, err
+	}
+	if err := r.applyService(ctx, js, newService(js, writerName(js), examplev1.DefaultServicePort)); err != nil {
+		return nil, err
+	}
+	return deploy, nil
+}
+
+// desiredWriterDeployment runs the single json-server that accepts writes.
+// It keeps data on the spec.storage claim or, without one, on an emptyDir
+// seeded on every start.
+func (r *JsonServerReconciler) desiredWriterDeployment(js *examplev1.JsonServer) *appsv1.Deployment {
+	deploy := r.podDeployment(js, writerName(js), 1)
+
+	if js.Spec.Storage != nil {
+		withSeededVolume(deploy, js, claimVolume(js), reseedPolicy(js))
+	} else {
+		withSeededVolume(deploy, js, corev1.VolumeSource{
+			EmptyDir: &corev1.EmptyDirVolumeSource{},
+		}, examplev1.ReseedAlways)
+	}
+
+	return deploy
+}
+
+// deleteOwned deletes the named object if this JsonServer controls it.
+func (r *JsonServerReconciler) deleteOwned(
+	ctx context.Context,
+	js *examplev1.JsonServer,
+	key types.NamespacedName,
+	obj client.Object,
+) error {
+	if err := r.Get(ctx, key, obj); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if !metav1.IsControlledBy(obj, js) {
+		return nil
+	}
+	return client.IgnoreNotFound(r.Delete(ctx, obj))
+}
+
+// -------------------- Readers --------------------
+
+// withWriterSync turns the replicas into readers of the writer: they serve
+// a local copy of its /db, refreshed by a sync sidecar, and forward every
+// write to it.
+func withWriterSync(deploy *appsv1.Deployment, js *examplev1.JsonServer) {
+	interval := examplev1.DefaultSyncIntervalSeconds
+	if js.Spec.Consistency != nil && js.Spec.Consistency.SyncIntervalSeconds > 0 {
+		interval = js.Spec.Consistency.SyncIntervalSeconds
+	}
+
+	// Seed from the ConfigMap so reads work before the first sync.
+	withSeededVolume(deploy, js, corev1.VolumeSource{
+		EmptyDir: &corev1.EmptyDirVolumeSource{},
+	}, examplev1.ReseedAlways)
+
+	pod := &deploy.Spec.Template.Spec
+	server := &pod.Containers[0]
+	writerEnv := corev1.EnvVar{Name: "WRITER_URL", Value: writerURL(js)}
+
+	// The forwarding middleware must run before any user middleware.
+	args := []string{server.Args[0], "--middlewares", path.Join(dataDir, forwardWritesMiddleware)}
+	args = append(args, server.Args[1:]...)
+	server.Args = append(args, "--watch")
+	server.Env = append(server.Env, writerEnv)
+
+	pod.Containers = append(pod.Containers, corev1.Container{
+		Name:            "sync",
+		Image:           server.Image,
+		ImagePullPolicy: server.ImagePullPolicy,
+		Command:         []string{"sh", "-c", syncScript},
+		Env: []corev1.EnvVar{
+			writerEnv,
+			{Name: "SYNC_INTERVAL", Value: fmt.Sprint(interval)},
+		},
+		VolumeMounts: []corev1.VolumeMount{
+			{Name: dataVolume, MountPath: dataDir},
+		},
+	})
+}
@@ -16,98 +16,375 @@ limitations under the License.
 
 package controller
 
-
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"path"
 	"reflect"
+	"slices"
+	"strconv"
+	"strings"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/events"
 
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	examplev1 "github.com/BlueTurtle-bytes/json-server/api/v1"
+	"github.com/BlueTurtle-bytes/json-server/internal/dataformat"
+)
+
+// Files rendered into the ConfigMap and mounted into the json-server container.
+const (
+	dataDir    = "/data"
+	dbFile     = "db.json"
+	routesFile = "routes.json"
 )
 
 // JsonServerReconciler reconciles a JsonServer object demo
 type JsonServerReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+
+	// DefaultImage is the operator-wide image reference; spec.image overrides it.
+	DefaultImage string
+
+	// HTTPClient reads live data from json-server pods for snapshots.
+	// A client with a short timeout is used when nil.
+	HTTPClient *http.Client
+
+	// Recorder emits Events about reconcile outcomes. No Events are
+	// emitted when nil.
+	Recorder events.EventRecorder
+
+	// APIReader reads Secrets without caching them. The client is used
+	// when nil.
+	APIReader client.Reader
 }
 
 // RBAC
 // +kubebuilder:rbac:groups=example.com,resources=jsonservers,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=example.com,resources=jsonservers/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=example.com,resources=jsonservers/finalizers,verbs=update
 // +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
-// +kubebuilder:rbac:groups="",resources=services;configmaps,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=apps,resources=replicasets,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=services;configmaps;persistentvolumeclaims,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=httproutes,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=coordination.k8s.io,resources=leases,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=events.k8s.io,resources=events,verbs=create;patch
 
 func (r *JsonServerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
 
 	var js examplev1.JsonServer
 	if err := r.Get(ctx, req.NamespacedName, &js); err != nil {
+		if apierrors.IsNotFound(err) {
+			forgetMetrics(req.NamespacedName)
+		}
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
+	// -------------------- Finalizer --------------------
+	if !js.DeletionTimestamp.IsZero() {
+		if !controllerutil.ContainsFinalizer(&js, examplev1.Finalizer) {
+			return ctrl.Result{}, nil
+		}
+		examplev1.SetDefaults(&js)
+		if err := r.finalize(ctx, &js); err != nil {
+			logger.Error(err, "failed to apply deletion policy", "policy", js.Spec.DeletionPolicy)
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+	if err := r.patchFinalizer(ctx, &js, controllerutil.AddFinalizer); err != nil {
+		logger.Error(err, "failed to add finalizer")
+		return ctrl.Result{}, err
+	}
+
+	// -------------------- Snapshot Promotion --------------------
+	if promoted, err := r.promoteSnapshot(ctx, &js); err != nil {
+		if errors.Is(err, errInvalidPromotion) {
+			logger.Info("snapshot cannot be promoted", "name", js.Name, "error", err)
+			r.invalidConfig(&js, js.Status.LastSnapshot.Message)
+			return ctrl.Result{}, r.updateStatus(ctx, &js)
+		}
+
+		logger.Error(err, "failed to promote snapshot", "snapshot", js.Annotations[examplev1.PromoteSnapshotAnnotation])
+		setCondition(&js, examplev1.ConditionConfigValid, metav1.ConditionFalse,
+			examplev1.ReasonReconcileFailed, "Error: unexpected failure")
+		return ctrl.Result{}, errors.Join(err, r.updateStatus(ctx, &js))
+	} else if promoted {
+		// The spec update triggers another reconcile with the new data.
+		return ctrl.Result{}, nil
+	}
+
+	// Objects admitted before the defaulting webhook, or with it disabled,
+	// get the same defaults in memory.
+	examplev1.SetDefaults(&js)
+
+	// -------------------- Data Source --------------------
+	if err := r.resolveDataFrom(ctx, &js); err != nil {
+		if errors.Is(err, errDataSourceNotFound) || errors.Is(err, errSecretNotAllowed) ||
+			errors.Is(err, errInvalidOpenAPI) {
+			logger.Info("data source not found", "name", js.Name, "error", err)
+			r.invalidConfig(&js, err.Error())
+			return ctrl.Result{}, r.updateStatus(ctx, &js)
+		}
+
+		logger.Error(err, "failed to read data source")
+		setCondition(&js, examplev1.ConditionConfigValid, metav1.ConditionFalse,
+			examplev1.ReasonReconcileFailed, "Error: unexpected failure")
+		return ctrl.Result{}, errors.Join(err, r.updateStatus(ctx, &js))
+	}
+
 	// -------------------- JSON Validation --------------------
-	var parsed any
-	if err := json.Unmarshal([]byte(js.Spec.JsonConfig), &parsed); err != nil {
+	// Data in other formats is served as JSON from here on.
+	format := dataFormat(&js)
+	decoded, err := dataformat.Decode([]byte(js.Spec.JsonConfig), format)
+	if err != nil {
 		logger.Info("invalid jsonConfig detected", "name", js.Name, "error", err)
 
-		r.updateStatus(ctx, &js, "Error", "Error: spec.jsonConfig is not a valid json object")
+		r.invalidConfig(&js, fmt.Sprintf("Error: %s is not valid %s: %v", dataSourceName(&js), format, err))
 
 		// Stop reconciliation – do NOT create/update resources
-		return ctrl.Result{}, nil
+		return ctrl.Result{}, r.updateStatus(ctx, &js)
 	}
+	js.Spec.JsonConfig = string(decoded)
 
-	if err := r.reconcileConfigMap(ctx, &js); err != nil {
-		logger.Error(err, "failed to reconcile ConfigMap")
-		r.updateStatus(ctx, &js, "Error", "Error: unexpected failure")
-		return ctrl.Result{}, err
+	// -------------------- Collections --------------------
+	if err := r.composeCollections(ctx, &js); err != nil {
+		if errors.Is(err, errDataSourceNotFound) || errors.Is(err, errInvalidCollection) {
+			logger.Info("invalid collection", "name", js.Name, "error", err)
+			r.invalidConfig(&js, err.Error())
+			return ctrl.Result{}, r.updateStatus(ctx, &js)
+		}
+
+		logger.Error(err, "failed to read collections")
+		setCondition(&js, examplev1.ConditionConfigValid, metav1.ConditionFalse,
+			examplev1.ReasonReconcileFailed, "Error: unexpected failure")
+		return ctrl.Result{}, errors.Join(err, r.updateStatus(ctx, &js))
 	}
 
+	// -------------------- Shape Validation --------------------
+	if errs := examplev1.ValidateDB([]byte(js.Spec.JsonConfig), js.Spec.Server, dbPath(&js)); len(errs) > 0 {
+		logger.Info("db.json has an invalid shape", "name", js.Name, "error", errs.ToAggregate())
 
+		r.invalidConfig(&js, "Error: "+errs.ToAggregate().Error())
+		return ctrl.Result{}, r.updateStatus(ctx, &js)
+	}
+
+	// -------------------- Schema Validation --------------------
+	schemas, err := r.resolveSchemas(ctx, &js)
+	if err != nil {
+		if errors.Is(err, errDataSourceNotFound) || errors.Is(err, errInvalidSchema) {
+			logger.Info("invalid schema", "name", js.Name, "error", err)
+			r.invalidConfig(&js, err.Error())
+			return ctrl.Result{}, r.updateStatus(ctx, &js)
+		}
+
+		logger.Error(err, "failed to read schemas")
+		setCondition(&js, examplev1.ConditionConfigValid, metav1.ConditionFalse,
+			examplev1.ReasonReconcileFailed, "Error: unexpected failure")
+		return ctrl.Result{}, errors.Join(err, r.updateStatus(ctx, &js))
+	}
+	errs := examplev1.ValidateSchemas([]byte(js.Spec.JsonConfig), schemas, dbPath(&js))
+	switch {
+	case len(schemas) == 0:
+		meta.RemoveStatusCondition(&js.Status.Conditions, examplev1.ConditionSchemaValid)
+	case len(errs) == 0:
+		setCondition(&js, examplev1.ConditionSchemaValid, metav1.ConditionTrue,
+			examplev1.ReasonValid, "every item matches the schema of its collection")
+	case js.Spec.SchemaEnforcement == examplev1.SchemaEnforcementWarn:
+		setCondition(&js, examplev1.ConditionSchemaValid, metav1.ConditionFalse,
+			examplev1.ReasonSchemaMismatch, errs.ToAggregate().Error())
+	default:
+		logger.Info("db.json does not match its schemas", "name", js.Name, "error", errs.ToAggregate())
+
+		setCondition(&js, examplev1.ConditionSchemaValid, metav1.ConditionFalse,
+			examplev1.ReasonSchemaMismatch, errs.ToAggregate().Error())
+		r.invalidConfig(&js, "Error: "+errs.ToAggregate().Error())
+		return ctrl.Result{}, r.updateStatus(ctx, &js)
+	}
+
+	data, binaryData, err := renderConfigMap(&js)
+	if err != nil {
+		logger.Info("rendered config does not fit into a ConfigMap", "name", js.Name, "error", err)
+
+		r.invalidConfig(&js, err.Error())
+		return ctrl.Result{}, r.updateStatus(ctx, &js)
+	}
+
+	if err := r.reconcileConfig(ctx, &js, data, binaryData); err != nil {
+		logger.Error(err, "failed to reconcile ConfigMap")
+		reconcileErrors.WithLabelValues(phaseConfigMap).Inc()
+		setCondition(&js, examplev1.ConditionConfigValid, metav1.ConditionFalse,
+			examplev1.ReasonReconcileFailed, "Error: unexpected failure")
+		return ctrl.Result{}, errors.Join(err, r.updateStatus(ctx, &js))
+	}
+	source, rendered := dataSourceName(&js), "ConfigMap"
+	if composed(&js) {
+		source = fmt.Sprintf("db.json with %d collections", len(js.Status.Collections))
+	}
+	if secretBacked(&js) {
+		rendered = "Secret"
+	}
+	setCondition(&js, examplev1.ConditionConfigValid, metav1.ConditionTrue,
+		examplev1.ReasonValid, fmt.Sprintf("%s is valid and rendered into the %s", source, rendered))
+	recordConfigSize(&js)
+
+	pvc, err := r.reconcilePVC(ctx, &js)
+	if err != nil {
+		logger.Error(err, "failed to reconcile PersistentVolumeClaim")
+		reconcileErrors.WithLabelValues(phaseStorage).Inc()
+		setCondition(&js, examplev1.ConditionStorageReady, metav1.ConditionFalse,
+			examplev1.ReasonReconcileFailed, "Error: unexpected failure")
+		return ctrl.Result{}, errors.Join(err, r.updateStatus(ctx, &js))
+	}
+	if pvc != nil {
+		setCondition(&js, examplev1.ConditionStorageReady, metav1.ConditionTrue,
+			examplev1.ReasonAvailable, fmt.Sprintf("PersistentVolumeClaim is %s", pvcPhase(pvc)))
+	} else {
+		meta.RemoveStatusCondition(&js.Status.Conditions, examplev1.ConditionStorageReady)
+	}
 
 	deploy, err := r.reconcileDeployment(ctx, &js)
 	if err != nil {
 		logger.Error(err, "failed to reconcile Deployment")
-		r.updateStatus(ctx, &js, "Error", "Error: unexpected failure")
-		return ctrl.Result{}, err
+		reconcileErrors.WithLabelValues(phaseDeployment).Inc()
+		setCondition(&js, examplev1.ConditionDeploymentAvailable, metav1.ConditionFalse,
+			examplev1.ReasonReconcileFailed, "Error: unexpected failure")
+		return ctrl.Result{}, errors.Join(err, r.updateStatus(ctx, &js))
+	}
+
+	writer, err := r.reconcileWriter(ctx, &js)
+	if err != nil {
+		logger.Error(err, "failed to reconcile writer")
+		reconcileErrors.WithLabelValues(phaseDeployment).Inc()
+		setCondition(&js, examplev1.ConditionDeploymentAvailable, metav1.ConditionFalse,
+			examplev1.ReasonReconcileFailed, "Error: unexpected failure")
+		return ctrl.Result{}, errors.Join(err, r.updateStatus(ctx, &js))
 	}
 
 	// Accurate replica reporting
 	js.Status.Replicas = deploy.Status.ReadyReplicas
+	js.Status.ConfigHash = configHash(configMapData(&js))
+	replicaSets, err := r.replicaSetStatuses(ctx, deploy, writer)
+	if err != nil {
+		logger.Error(err, "failed to list ReplicaSets")
+		reconcileErrors.WithLabelValues(phaseDeployment).Inc()
+		setCondition(&js, examplev1.ConditionDeploymentAvailable, metav1.ConditionFalse,
+			examplev1.ReasonReconcileFailed, "Error: unexpected failure")
+		return ctrl.Result{}, errors.Join(err, r.updateStatus(ctx, &js))
+	}
+	js.Status.ReplicaSets = replicaSets
+	available, message := deploymentAvailable(deploy)
+	if writer != nil {
+		if writerAvailable, writerMessage := deploymentAvailable(writer); !writerAvailable {
+			available, message = false, "writer: "+writerMessage
+		}
+	}
+	if available {
+		if !meta.IsStatusConditionTrue(js.Status.Conditions, examplev1.ConditionDeploymentAvailable) {
+			r.event(&js, deploy, corev1.EventTypeNormal, eventReasonRolloutCompleted, "Rollout",
+				"Rollout of config %s completed: %s", js.Status.ConfigHash, message)
+		}
+		setCondition(&js, examplev1.ConditionDeploymentAvailable, metav1.ConditionTrue,
+			examplev1.ReasonAvailable, message)
+	} else {
+		setCondition(&js, examplev1.ConditionDeploymentAvailable, metav1.ConditionFalse,
+			examplev1.ReasonProgressing, message)
+	}
 
 	if err := r.reconcileService(ctx, &js); err != nil {
 		logger.Error(err, "failed to reconcile Service")
-		r.updateStatus(ctx, &js, "Error", "Error: unexpected failure")
-		return ctrl.Result{}, err
+		reconcileErrors.WithLabelValues(phaseService).Inc()
+		setCondition(&js, examplev1.ConditionServiceReady, metav1.ConditionFalse,
+			examplev1.ReasonReconcileFailed, "Error: unexpected failure")
+		return ctrl.Result{}, errors.Join(err, r.updateStatus(ctx, &js))
+	}
+	setCondition(&js, examplev1.ConditionServiceReady, metav1.ConditionTrue,
+		examplev1.ReasonAvailable, "Service is reconciled")
+
+	url, err := r.reconcileIngress(ctx, &js)
+	if err != nil {
+		logger.Error(err, "failed to reconcile Ingress")
+		reconcileErrors.WithLabelValues(phaseIngress).Inc()
+		setCondition(&js, examplev1.ConditionIngressReady, metav1.ConditionFalse,
+			examplev1.ReasonReconcileFailed, "Error: unexpected failure")
+		return ctrl.Result{}, errors.Join(err, r.updateStatus(ctx, &js))
+	}
+	js.Status.URL = url
+	switch {
+	case js.Spec.Ingress == nil:
+		meta.RemoveStatusCondition(&js.Status.Conditions, examplev1.ConditionIngressReady)
+	case js.Spec.Ingress.Gateway != nil:
+		setCondition(&js, examplev1.ConditionIngressReady, metav1.ConditionTrue,
+			examplev1.ReasonAvailable, "HTTPRoute is reconciled")
+	default:
+		setCondition(&js, examplev1.ConditionIngressReady, metav1.ConditionTrue,
+			examplev1.ReasonAvailable, "Ingress is reconciled")
 	}
 
-	// replicas := int32(1)
-	// if js.Spec.Replicas != nil {
-	// 	replicas = *js.Spec.Replicas
-	// }
-	// // Sync replicas into status for scale subresource
-	// js.Status.Replicas = replicas
+	if err := r.reconcileSnapshot(ctx, &js); err != nil {
+		logger.Error(err, "failed to take snapshot")
+		return ctrl.Result{}, errors.Join(err, r.updateStatus(ctx, &js))
+	}
 
-	r.updateStatus(ctx, &js, "Synced", "Synced successfully!")
-	return ctrl.Result{}, nil
+	return ctrl.Result{}, r.updateStatus(ctx, &js)
 }
 
 // -------------------- ConfigMap --------------------
 
-func (r *JsonServerReconciler) reconcileConfigMap(ctx context.Context, js *examplev1.JsonServer) error {
+// reconcileConfig renders the files into the ConfigMap, or into a Secret
+// when db.json comes from a Secret, and deletes the other one.
+func (r *JsonServerReconciler) reconcileConfig(
+	ctx context.Context,
+	js *examplev1.JsonServer,
+	data map[string]string,
+	binaryData map[string][]byte,
+) error {
+
+	if secretBacked(js) {
+		if err := r.reconcileSecret(ctx, js, data, binaryData); err != nil {
+			return err
+		}
+		return r.deleteRendered(ctx, js, &corev1.ConfigMap{})
+	}
+	if err := r.reconcileConfigMap(ctx, js, data, binaryData); err != nil {
+		return err
+	}
+	return r.deleteRendered(ctx, js, secretMetadata())
+}
+
+func (r *JsonServerReconciler) reconcileConfigMap(
+	ctx context.Context,
+	js *examplev1.JsonServer,
+	data map[string]string,
+	binaryData map[string][]byte,
+) error {
+
 	cm := &corev1.ConfigMap{}
 	err := r.Get(ctx, types.NamespacedName{
 		Name:      js.Name,
@@ -119,50 +396,98 @@ func (r *JsonServerReconciler) reconcileConfigMap(ctx context.Context, js *examp
 			Name:      js.Name,
 			Namespace: js.Namespace,
 		},
-		Data: map[string]string{
-			"db.json": js.Spec.JsonConfig,
-		},
+		Data:       data,
+		BinaryData: binaryData,
 	}
 
 	if apierrors.IsNotFound(err) {
 		if err := controllerutil.SetControllerReference(js, desired, r.Scheme); err != nil {
 			return err
 		}
-		return r.Create(ctx, desired)
+		if err := r.Create(ctx, desired); err != nil {
+			return err
+		}
+		r.childEvent(js, desired, "ConfigMap", eventReasonCreated)
+		return nil
 	}
 
 	if err != nil {
 		return err
 	}
 
-	if !reflect.DeepEqual(cm.Data, desired.Data) {
+	// Semantic equality treats an empty map like the nil the API server returns.
+	if !equality.Semantic.DeepEqual(cm.Data, desired.Data) ||
+		!equality.Semantic.DeepEqual(cm.BinaryData, desired.BinaryData) {
 		cm.Data = desired.Data
-		return r.Update(ctx, cm)
+		cm.BinaryData = desired.BinaryData
+		if err := r.Update(ctx, cm); err != nil {
+			return err
+		}
+		r.childEvent(js, cm, "ConfigMap", eventReasonUpdated)
 	}
 
 	return nil
 }
 
-// -------------------- Deployment --------------------
+// configMapData renders db.json plus the optional routes.json and
+// middleware files configured in spec.server, and the write forwarding
+// middleware in SingleWriter mode.
+func configMapData(js *examplev1.JsonServer) map[string]string {
+	data := map[string]string{
+		dbFile: js.Spec.JsonConfig,
+	}
 
+	if server := js.Spec.Server; server != nil {
+		if len(server.Routes) > 0 {
+			// json.Marshal sorts map keys, so the rendered file is stable.
+			routes, _ := json.Marshal(server.Routes)
+			data[routesFile] = string(routes)
+		}
+		for name, source := range server.Middlewares {
+			data[name] = source
+		}
+	}
+
+	if consistencyMode(js) == examplev1.ConsistencySingleWriter {
+		data[forwardWritesMiddleware] = forwardWritesScript
+	}
+
+	return data
+}
+
+// configHash identifies the rendered ConfigMap. json-server reads its files
+// only at startup, so the hash is put on the pod template to roll the pods
+// whenever the data changes.
+func configHash(data map[string]string) string {
+	h := sha256.New()
+	for _, key := range slices.Sorted(maps.Keys(data)) {
+		// Length prefixes keep key and value boundaries unambiguous.
+		fmt.Fprintf(h, "%d:%s%d:%s", len(key), key, len(data[key]), data[key])
+	}
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
+
+// -------------------- Deployment --------------------
 
 func (r *JsonServerReconciler) reconcileDeployment(
 	ctx context.Context,
 	js *examplev1.JsonServer,
 ) (*appsv1.Deployment, error) {
+	return r.applyDeployment(ctx, js, r.desiredDeployment(js, desiredReplicas(js)))
+}
 
-	deploy := &appsv1.Deployment{}
-	err := r.Get(ctx, types.NamespacedName{
-		Name:      js.Name,
-		Namespace: js.Namespace,
-	}, deploy)
+// applyDeployment creates the desired Deployment or brings the existing one
+// in line with it.
+func (r *JsonServerReconciler) applyDeployment(
+	ctx context.Context,
+	js *examplev1.JsonServer,
+	desired *appsv1.Deployment,
+) (*appsv1.Deployment, error) {
 
-	replicas := int32(1)
-	if js.Spec.Replicas != nil {
-		replicas = *js.Spec.Replicas
-	}
+	deploy := &appsv1.Deployment{}
+	err := r.Get(ctx, client.ObjectKeyFromObject(desired), deploy)
 
-	desired := desiredDeployment(js, replicas)
+	replicas := *desired.Spec.Replicas
 
 	if apierrors.IsNotFound(err) {
 		if err := controllerutil.SetControllerReference(js, desired, r.Scheme); err != nil {
@@ -171,6 +496,8 @@ func (r *JsonServerReconciler) reconcileDeployment(
 		if err := r.Create(ctx, desired); err != nil {
 			return nil, err
 		}
+		r.childEvent(js, desired, "Deployment", eventReasonCreated)
+		r.rolloutEvent(js, desired)
 		return desired, nil
 	}
 
@@ -188,11 +515,21 @@ func (r *JsonServerReconciler) reconcileDeployment(
 		updated = true
 	}
 
+	// --------------------
+	// Reconcile strategy
+	// (Recreate is required for a ReadWriteOnce claim)
+	// --------------------
+	if deploy.Spec.Strategy.Type != desired.Spec.Strategy.Type {
+		deploy.Spec.Strategy = desired.Spec.Strategy
+		updated = true
+	}
+
 	// --------------------
 	// Reconcile pod template
 	// (this triggers rollout)
 	// --------------------
-	if !reflect.DeepEqual(deploy.Spec.Template, desired.Spec.Template) {
+	rollout := !reflect.DeepEqual(deploy.Spec.Template, desired.Spec.Template)
+	if rollout {
 		deploy.Spec.Template = desired.Spec.Template
 		updated = true
 	}
@@ -204,60 +541,135 @@ func (r *JsonServerReconciler) reconcileDeployment(
 		if err := r.Update(ctx, deploy); err != nil {
 			return nil, err
 		}
+		r.childEvent(js, deploy, "Deployment", eventReasonUpdated)
+	}
+	if rollout {
+		r.rolloutEvent(js, deploy)
 	}
 
 	return deploy, nil
 }
 
-func desiredDeployment(js *examplev1.JsonServer, replicas int32) *appsv1.Deployment {
+// replicaSetStatuses reports the config hash served by every ReplicaSet of
+// the Deployments that still runs pods. Writer may be nil.
+func (r *JsonServerReconciler) replicaSetStatuses(
+	ctx context.Context,
+	deploy, writer *appsv1.Deployment,
+) ([]examplev1.ReplicaSetStatus, error) {
+
+	var statuses []examplev1.ReplicaSetStatus
+	for _, d := range []*appsv1.Deployment{deploy, writer} {
+		if d == nil {
+			continue
+		}
+
+		var list appsv1.ReplicaSetList
+		if err := r.List(ctx, &list,
+			client.InNamespace(d.Namespace),
+			client.MatchingLabels(d.Spec.Selector.MatchLabels),
+		); err != nil {
+			return nil, err
+		}
+
+		for _, rs := range list.Items {
+			if !metav1.IsControlledBy(&rs, d) || rs.Status.Replicas == 0 {
+				continue
+			}
+			statuses = append(statuses, examplev1.ReplicaSetStatus{
+				Name:          rs.Name,
+				ConfigHash:    rs.Spec.Template.Annotations[examplev1.ConfigHashAnnotation],
+				Replicas:      rs.Status.Replicas,
+				ReadyReplicas: rs.Status.ReadyReplicas,
+			})
+		}
+	}
+
+	slices.SortFunc(statuses, func(a, b examplev1.ReplicaSetStatus) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return statuses, nil
+}
+
+func (r *JsonServerReconciler) desiredDeployment(js *examplev1.JsonServer, replicas int32) *appsv1.Deployment {
+	deploy := r.podDeployment(js, js.Name, replicas)
+
+	switch {
+	case consistencyMode(js) == examplev1.ConsistencySingleWriter:
+		// The writer owns the claim; replicas serve a synced copy.
+		withWriterSync(deploy, js)
+	case js.Spec.Storage != nil:
+		withSeededVolume(deploy, js, claimVolume(js), reseedPolicy(js))
+	case compressDB(js):
+		// The compressed db.json is unpacked onto a volume at every start.
+		withSeededVolume(deploy, js, corev1.VolumeSource{
+			EmptyDir: &corev1.EmptyDirVolumeSource{},
+		}, examplev1.ReseedAlways)
+	}
+
+	return deploy
+}
+
+// podDeployment builds a json-server Deployment serving the ConfigMap, or
+// the Secret of a Secret-backed instance, read-only from /data. Its pods are labelled app=<name>.
+func (r *JsonServerReconciler) podDeployment(js *examplev1.JsonServer, name string, replicas int32) *appsv1.Deployment {
+	var pullSecrets []corev1.LocalObjectReference
+	if js.Spec.Image != nil {
+		pullSecrets = js.Spec.Image.ImagePullSecrets
+	}
+
+	image := resolveImage(r.defaultImage(), js.Spec.Image)
+	pullPolicy := imagePullPolicy(image, js.Spec.Image)
+
 	return &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      js.Name,
+			Name:      name,
 			Namespace: js.Namespace,
 		},
 		Spec: appsv1.DeploymentSpec{
 			Replicas: &replicas,
+			Strategy: appsv1.DeploymentStrategy{
+				Type: appsv1.RollingUpdateDeploymentStrategyType,
+			},
 			Selector: &metav1.LabelSelector{
 				MatchLabels: map[string]string{
-					"app": js.Name,
+					"app": name,
 				},
 			},
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
 					Labels: map[string]string{
-						"app": js.Name,
+						"app": name,
+					},
+					Annotations: map[string]string{
+						examplev1.ConfigHashAnnotation: configHash(configMapData(js)),
 					},
 				},
 				Spec: corev1.PodSpec{
+					ImagePullSecrets: pullSecrets,
 					Containers: []corev1.Container{
 						{
-							Name:  "json-server",
-							Image: "backplane/json-server",
-							Args:  []string{"/data/db.json"},
+							Name:            "json-server",
+							Image:           image,
+							ImagePullPolicy: pullPolicy,
+							Args:            serverArgs(js),
 							Ports: []corev1.ContainerPort{
 								{
-									ContainerPort: 3000,
+									ContainerPort: serverPort(js),
 									Protocol:      corev1.ProtocolTCP,
 								},
 							},
 							VolumeMounts: []corev1.VolumeMount{
 								{
-									Name:      "json-config",
-									MountPath: "/data",
+									Name:      configVolume,
+									MountPath: dataDir,
 								},
 							},
 						},
 					},
 					Volumes: []corev1.Volume{
 						{
-							Name: "json-config",
-							VolumeSource: corev1.VolumeSource{
-								ConfigMap: &corev1.ConfigMapVolumeSource{
-									LocalObjectReference: corev1.LocalObjectReference{
-										Name: js.Name,
-									},
-								},
-							},
+							Name:         configVolume,
+							VolumeSource: configVolumeSource(js),
 						},
 					},
 				},
@@ -266,62 +678,395 @@ func desiredDeployment(js *examplev1.JsonServer, replicas int32) *appsv1.Deploym
 	}
 }
 
+func (r *JsonServerReconciler) defaultImage() string {
+	if r.DefaultImage == "" {
+		return examplev1.DefaultImage
+	}
+	return r.DefaultImage
+}
+
+func desiredReplicas(js *examplev1.JsonServer) int32 {
+	if js.Spec.Replicas != nil {
+		return *js.Spec.Replicas
+	}
+	return examplev1.DefaultReplicas
+}
+
+func serverPort(js *examplev1.JsonServer) int32 {
+	if js.Spec.Port != 0 {
+		return js.Spec.Port
+	}
+	return examplev1.DefaultPort
+}
+
+// serverArgs builds the json-server command line from spec.server and
+// spec.consistency.
+func serverArgs(js *examplev1.JsonServer) []string {
+	args := []string{path.Join(dataDir, dbFile), "--port", strconv.Itoa(int(serverPort(js)))}
+
+	server := js.Spec.Server
+	if server == nil {
+		server = &examplev1.ServerSpec{}
+	}
+
+	if len(server.Routes) > 0 {
+		args = append(args, "--routes", path.Join(dataDir, routesFile))
+	}
+	for _, name := range slices.Sorted(maps.Keys(server.Middlewares)) {
+		args = append(args, "--middlewares", path.Join(dataDir, name))
+	}
+	if server.DelayMs != nil && *server.DelayMs > 0 {
+		args = append(args, "--delay", strconv.Itoa(int(*server.DelayMs)))
+	}
+	if server.ReadOnly ||
+		(consistencyMode(js) == examplev1.ConsistencyReadOnlyWhenScaled && desiredReplicas(js) > 1) {
+		args = append(args, "--read-only")
+	}
+	if server.IDField != "" {
+		args = append(args, "--id", server.IDField)
+	}
+	if server.ForeignKeySuffix != "" {
+		args = append(args, "--foreignKeySuffix", server.ForeignKeySuffix)
+	}
+
+	return args
+}
+
+// resolveImage merges spec.image over the operator default image reference.
+// A repository set on the CR drops the default tag; a tag or digest set on
+// the CR replaces the default one.
+func resolveImage(defaultImage string, spec *examplev1.ImageSpec) string {
+	repo, tag, digest := examplev1.SplitImage(defaultImage)
+	if spec == nil {
+		return defaultImage
+	}
+
+	if spec.Repository != "" {
+		repo, tag, digest = spec.Repository, "", ""
+	}
+	if spec.Tag != "" {
+		tag, digest = spec.Tag, ""
+	}
+	if spec.Digest != "" {
+		tag, digest = "", spec.Digest
+	}
+
+	switch {
+	case digest != "":
+		return repo + "@" + digest
+	case tag != "":
+		return repo + ":" + tag
+	default:
+		return repo
+	}
+}
+
+// imagePullPolicy returns the pull policy of spec.image, or the one
+// Kubernetes would pick for the resolved image: Always for an untagged or
+// latest image, IfNotPresent otherwise. It is set explicitly so the pod
+// template compares equal to the one the API server stores.
+func imagePullPolicy(image string, spec *examplev1.ImageSpec) corev1.PullPolicy {
+	if spec != nil && spec.PullPolicy != "" {
+		return spec.PullPolicy
+	}
+	_, tag, digest := examplev1.SplitImage(image)
+	if digest == "" && (tag == "" || tag == "latest") {
+		return corev1.PullAlways
+	}
+	return corev1.PullIfNotPresent
+}
+
 // -------------------- Service --------------------
 
 func (r *JsonServerReconciler) reconcileService(ctx context.Context, js *examplev1.JsonServer) error {
+	return r.applyService(ctx, js, desiredService(js))
+}
+
+// applyService creates the desired Service or brings the existing one in
+// line with it. Fields the API server allocates, such as the cluster IP and
+// unpinned node ports, are kept, and so are labels and annotations added by
+// others.
+func (r *JsonServerReconciler) applyService(
+	ctx context.Context,
+	js *examplev1.JsonServer,
+	desired *corev1.Service,
+) error {
+
 	svc := &corev1.Service{}
-	err := r.Get(ctx, types.NamespacedName{
-		Name:      js.Name,
-		Namespace: js.Namespace,
-	}, svc)
+	err := r.Get(ctx, client.ObjectKeyFromObject(desired), svc)
 
 	if apierrors.IsNotFound(err) {
-		desired := &corev1.Service{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:      js.Name,
-				Namespace: js.Namespace,
+		if err := controllerutil.SetControllerReference(js, desired, r.Scheme); err != nil {
+			return err
+		}
+		if err := r.Create(ctx, desired); err != nil {
+			return err
+		}
+		r.childEvent(js, desired, "Service", eventReasonCreated)
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	var labelsChanged, annotationsChanged bool
+	svc.Labels, labelsChanged = mergeStringMap(svc.Labels, desired.Labels)
+	svc.Annotations, annotationsChanged = mergeStringMap(svc.Annotations, desired.Annotations)
+	updated := labelsChanged || annotationsChanged
+
+	if svc.Spec.Type != desired.Spec.Type {
+		svc.Spec.Type = desired.Spec.Type
+		updated = true
+	}
+
+	if !reflect.DeepEqual(svc.Spec.Selector, desired.Spec.Selector) {
+		svc.Spec.Selector = desired.Spec.Selector
+		updated = true
+	}
+
+	ports := desired.Spec.Ports
+	if desired.Spec.Type != corev1.ServiceTypeClusterIP {
+		// Keep node ports the API server allocated for ports that do not pin one.
+		ports = slices.Clone(ports)
+		for i := range ports {
+			if ports[i].NodePort != 0 {
+				continue
+			}
+			for _, current := range svc.Spec.Ports {
+				if current.Name == ports[i].Name {
+					ports[i].NodePort = current.NodePort
+				}
+			}
+		}
+	}
+	if !reflect.DeepEqual(svc.Spec.Ports, ports) {
+		svc.Spec.Ports = ports
+		updated = true
+	}
+
+	if updated {
+		if err := r.Update(ctx, svc); err != nil {
+			return err
+		}
+		r.childEvent(js, svc, "Service", eventReasonUpdated)
+	}
+	return nil
+}
+
+// desiredService exposes spec.service.port on the pods of the instance.
+func desiredService(js *examplev1.JsonServer) *corev1.Service {
+	spec := js.Spec.Service
+	if spec == nil {
+		spec = &examplev1.ServiceSpec{}
+	}
+
+	svc := newService(js, js.Name, servicePort(js))
+	svc.Labels = spec.Labels
+	svc.Annotations = spec.Annotations
+	if spec.Type != "" {
+		svc.Spec.Type = spec.Type
+	}
+	if svc.Spec.Type != corev1.ServiceTypeClusterIP {
+		svc.Spec.Ports[0].NodePort = spec.NodePort
+	}
+	return svc
+}
+
+// newService builds a ClusterIP Service forwarding port to the json-server
+// port of the pods labelled app=<name>.
+func newService(js *examplev1.JsonServer, name string, port int32) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: js.Namespace,
+		},
+		Spec: corev1.ServiceSpec{
+			Type: corev1.ServiceTypeClusterIP,
+			Selector: map[string]string{
+				"app": name,
 			},
-			Spec: corev1.ServiceSpec{
-				Selector: map[string]string{
-					"app": js.Name,
-				},
-				Ports: []corev1.ServicePort{
-					{
-						Port:       3000,
-						TargetPort: intstr.FromInt(3000),
-					},
+			Ports: []corev1.ServicePort{
+				{
+					Name:       "http",
+					Protocol:   corev1.ProtocolTCP,
+					Port:       port,
+					TargetPort: intstr.FromInt32(serverPort(js)),
 				},
 			},
+		},
+	}
+}
+
+func servicePort(js *examplev1.JsonServer) int32 {
+	if js.Spec.Service != nil && js.Spec.Service.Port != 0 {
+		return js.Spec.Service.Port
+	}
+	return examplev1.DefaultServicePort
+}
+
+// mergeStringMap adds the desired entries to current, keeping entries set by
+// others, and reports whether anything changed.
+func mergeStringMap(current, desired map[string]string) (map[string]string, bool) {
+	changed := false
+	for key, value := range desired {
+		if v, ok := current[key]; !ok || v != value {
+			if current == nil {
+				current = map[string]string{}
+			}
+			current[key] = value
+			changed = true
 		}
+	}
+	return current, changed
+}
 
-		if err := controllerutil.SetControllerReference(js, desired, r.Scheme); err != nil {
-			return err
+// -------------------- Status --------------------
+
+// deploymentAvailable reports whether every desired replica of the
+// Deployment runs the current pod template and is available.
+func deploymentAvailable(deploy *appsv1.Deployment) (bool, string) {
+	desired := int32(1)
+	if deploy.Spec.Replicas != nil {
+		desired = *deploy.Spec.Replicas
+	}
+	message := fmt.Sprintf("%d/%d replicas available", deploy.Status.AvailableReplicas, desired)
+
+	if deploy.Status.ObservedGeneration < deploy.Generation {
+		return false, "Deployment spec change not yet observed"
+	}
+	if deploy.Status.UpdatedReplicas < desired || deploy.Status.AvailableReplicas < desired {
+		return false, message
+	}
+	for _, c := range deploy.Status.Conditions {
+		if c.Type == appsv1.DeploymentAvailable {
+			return c.Status == corev1.ConditionTrue, message
 		}
-		return r.Create(ctx, desired)
 	}
+	return false, message
+}
 
-	return err
+func setCondition(js *examplev1.JsonServer, conditionType string, status metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&js.Status.Conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             status,
+		ObservedGeneration: js.Generation,
+		Reason:             reason,
+		Message:            message,
+	})
 }
 
-// -------------------- Status --------------------
+// invalidConfig sets ConfigValid to False for a config the user has to fix.
+// The Warning Event is emitted only when the condition turns to it, not on
+// every reconcile of an object that stays invalid.
+func (r *JsonServerReconciler) invalidConfig(js *examplev1.JsonServer, message string) {
+	c := meta.FindStatusCondition(js.Status.Conditions, examplev1.ConditionConfigValid)
+	if c == nil || c.Status != metav1.ConditionFalse || c.Reason != examplev1.ReasonInvalidConfig {
+		r.event(js, nil, corev1.EventTypeWarning, examplev1.ReasonInvalidConfig, "Validate", "%s", message)
+	}
+	setCondition(js, examplev1.ConditionConfigValid, metav1.ConditionFalse, examplev1.ReasonInvalidConfig, message)
+}
 
-func (r *JsonServerReconciler) updateStatus(
-	ctx context.Context,
-	js *examplev1.JsonServer,
-	state, message string,
-) {
+// summarizeStatus derives the Ready condition and the State/Message summary
+// from the per-phase conditions. A phase that has not run yet keeps Ready
+// Unknown; an invalid config or failed reconcile puts the summary in Error.
+func summarizeStatus(js *examplev1.JsonServer) {
+	ready := metav1.Condition{
+		Type:    examplev1.ConditionReady,
+		Status:  metav1.ConditionTrue,
+		Reason:  examplev1.ReasonAvailable,
+		Message: "JsonServer is ready",
+	}
+	state, message := examplev1.StateSynced, "Synced successfully!"
+
+	phases := []string{
+		examplev1.ConditionConfigValid,
+		examplev1.ConditionDeploymentAvailable,
+		examplev1.ConditionServiceReady,
+	}
+	if js.Spec.Storage != nil {
+		phases = append(phases, examplev1.ConditionStorageReady)
+	}
+	if js.Spec.Ingress != nil {
+		phases = append(phases, examplev1.ConditionIngressReady)
+	}
+
+	for _, t := range phases {
+		c := meta.FindStatusCondition(js.Status.Conditions, t)
+		switch {
+		case c == nil:
+			if ready.Status == metav1.ConditionTrue {
+				ready.Status = metav1.ConditionUnknown
+				ready.Reason = examplev1.ReasonReconciling
+				ready.Message = t + " has not been evaluated yet"
+			}
+		case c.Status != metav1.ConditionTrue:
+			if ready.Status != metav1.ConditionFalse {
+				ready.Status = metav1.ConditionFalse
+				ready.Reason = c.Reason
+				ready.Message = c.Message
+			}
+			if state != examplev1.StateError &&
+				(c.Reason == examplev1.ReasonInvalidConfig || c.Reason == examplev1.ReasonReconcileFailed) {
+				state, message = examplev1.StateError, c.Message
+			}
+		}
+	}
+
+	setCondition(js, ready.Type, ready.Status, ready.Reason, ready.Message)
 	js.Status.State = state
 	js.Status.Message = message
-	_ = r.Status().Update(ctx, js)
+}
+
+// updateStatus summarizes and writes the status and reports it in the
+// metrics. Conflicts and deleted objects are ignored, since a newer version
+// of the object is reconciled next; other failures are reported in an Event
+// and returned so the request is retried.
+func (r *JsonServerReconciler) updateStatus(ctx context.Context, js *examplev1.JsonServer) error {
+	summarizeStatus(js)
+	js.Status.ObservedGeneration = js.Generation
+
+	err := r.Status().Update(ctx, js)
+	if !apierrors.IsNotFound(err) {
+		recordStatusMetrics(js, err == nil)
+	}
+	if err == nil || apierrors.IsConflict(err) || apierrors.IsNotFound(err) {
+		return nil
+	}
+	log.FromContext(ctx).Error(err, "failed to update status")
+	r.event(js, nil, corev1.EventTypeWarning, eventReasonStatusUpdateFailed, "UpdateStatus",
+		"Failed to update status: %v", err)
+	return err
 }
 
 // -------------------- Setup --------------------
 
 func (r *JsonServerReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(),
+		&examplev1.JsonServer{}, dataSourceIndex, dataSources); err != nil {
+		return err
+	}
+
+	b := ctrl.NewControllerManagedBy(mgr).
 		For(&examplev1.JsonServer{}).
 		Owns(&appsv1.Deployment{}).
 		Owns(&corev1.Service{}).
 		Owns(&corev1.ConfigMap{}).
-		Complete(r)
+		Owns(&corev1.PersistentVolumeClaim{}).
+		Owns(&networkingv1.Ingress{}).
+		Watches(&corev1.ConfigMap{},
+			handler.EnqueueRequestsFromMapFunc(r.requestsForDataSource("ConfigMap"))).
+		// Secrets are watched as metadata only, so their data is not cached.
+		Owns(&corev1.Secret{}, builder.OnlyMetadata).
+		WatchesMetadata(&corev1.Secret{},
+			handler.EnqueueRequestsFromMapFunc(r.requestsForDataSource("Secret")))
+
+	// HTTPRoutes can only be watched when the Gateway API CRDs are installed.
+	if _, err := mgr.GetRESTMapper().RESTMapping(httpRouteGVK.GroupKind(), httpRouteGVK.Version); err == nil {
+		b = b.Owns(newHTTPRoute())
+	} else if !meta.IsNoMatchError(err) {
+		return err
+	}
+
+	return b.Complete(r)
 }
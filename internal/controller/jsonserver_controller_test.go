@@ -1,19 +1,43 @@
 package controller
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	examplev1 "github.com/BlueTurtle-bytes/json-server/api/v1"
+	"github.com/BlueTurtle-bytes/json-server/internal/dataformat"
 )
 
 var _ = Describe("JsonServer Controller", func() {
@@ -85,5 +109,1531 @@ var _ = Describe("JsonServer Controller", func() {
 			}).Should(Equal("Synced"))
 		})
 
+		It("should revert manual edits to the Service", func() {
+			By("Waiting for Service to be created")
+			svc := &corev1.Service{}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, namespacedName, svc)
+			}).Should(Succeed())
+
+			By("Editing the Service selector and port")
+			svc.Spec.Selector = map[string]string{"app": "other"}
+			svc.Spec.Ports[0].Port = 8080
+			Expect(k8sClient.Update(ctx, svc)).To(Succeed())
+
+			By("Waiting for the controller to restore them")
+			Eventually(func(g Gomega) {
+				svc := &corev1.Service{}
+				g.Expect(k8sClient.Get(ctx, namespacedName, svc)).To(Succeed())
+				g.Expect(svc.Spec.Selector).To(Equal(map[string]string{"app": resourceName}))
+				g.Expect(svc.Spec.Ports[0].Port).To(Equal(int32(3000)))
+			}).Should(Succeed())
+		})
+
+		It("should roll the pods when jsonConfig changes", func() {
+			By("Waiting for the config hash to be reported")
+			var hash string
+			Eventually(func(g Gomega) {
+				js := &examplev1.JsonServer{}
+				g.Expect(k8sClient.Get(ctx, namespacedName, js)).To(Succeed())
+				g.Expect(js.Status.ConfigHash).NotTo(BeEmpty())
+				hash = js.Status.ConfigHash
+
+				deploy := &appsv1.Deployment{}
+				g.Expect(k8sClient.Get(ctx, namespacedName, deploy)).To(Succeed())
+				g.Expect(deploy.Spec.Template.Annotations).To(
+					HaveKeyWithValue(examplev1.ConfigHashAnnotation, hash))
+			}).Should(Succeed())
+
+			By("Changing jsonConfig")
+			js := &examplev1.JsonServer{}
+			Expect(k8sClient.Get(ctx, namespacedName, js)).To(Succeed())
+			js.Spec.JsonConfig = `{"people": []}`
+			Expect(k8sClient.Update(ctx, js)).To(Succeed())
+
+			By("Waiting for the pod template to carry the new hash")
+			Eventually(func(g Gomega) {
+				deploy := &appsv1.Deployment{}
+				g.Expect(k8sClient.Get(ctx, namespacedName, deploy)).To(Succeed())
+				g.Expect(deploy.Spec.Template.Annotations[examplev1.ConfigHashAnnotation]).NotTo(Equal(hash))
+			}).Should(Succeed())
+		})
+
+		It("should report conditions and observedGeneration", func() {
+			By("Waiting for ConfigValid and ServiceReady to be True")
+			Eventually(func(g Gomega) {
+				js := &examplev1.JsonServer{}
+				g.Expect(k8sClient.Get(ctx, namespacedName, js)).To(Succeed())
+				g.Expect(js.Status.ObservedGeneration).To(Equal(js.Generation))
+				g.Expect(meta.IsStatusConditionTrue(js.Status.Conditions, examplev1.ConditionConfigValid)).To(BeTrue())
+				g.Expect(meta.IsStatusConditionTrue(js.Status.Conditions, examplev1.ConditionServiceReady)).To(BeTrue())
+				// envtest runs no Deployment controller, so pods never become available
+				g.Expect(meta.FindStatusCondition(js.Status.Conditions, examplev1.ConditionDeploymentAvailable)).NotTo(BeNil())
+				g.Expect(meta.FindStatusCondition(js.Status.Conditions, examplev1.ConditionReady)).NotTo(BeNil())
+			}).Should(Succeed())
+
+			By("Breaking jsonConfig")
+			js := &examplev1.JsonServer{}
+			Expect(k8sClient.Get(ctx, namespacedName, js)).To(Succeed())
+			js.Spec.JsonConfig = `{ invalid json }`
+			Expect(k8sClient.Update(ctx, js)).To(Succeed())
+
+			By("Waiting for ConfigValid and Ready to be False")
+			Eventually(func(g Gomega) {
+				js := &examplev1.JsonServer{}
+				g.Expect(k8sClient.Get(ctx, namespacedName, js)).To(Succeed())
+				g.Expect(js.Status.ObservedGeneration).To(Equal(js.Generation))
+				g.Expect(meta.IsStatusConditionFalse(js.Status.Conditions, examplev1.ConditionConfigValid)).To(BeTrue())
+				g.Expect(meta.IsStatusConditionFalse(js.Status.Conditions, examplev1.ConditionReady)).To(BeTrue())
+				g.Expect(js.Status.State).To(Equal(examplev1.StateError))
+			}).Should(Succeed())
+		})
+
+	})
+
+	Context("When resolving the container image", func() {
+		It("should use the operator default when spec.image is unset", func() {
+			Expect(resolveImage("backplane/json-server:0.17", nil)).To(Equal("backplane/json-server:0.17"))
+		})
+
+		It("should let spec.image override repository, tag and digest", func() {
+			const def = "registry.local:5000/backplane/json-server:0.17"
+			digest := "sha256:" + strings.Repeat("a", 64)
+
+			Expect(resolveImage(def, &examplev1.ImageSpec{Tag: "1.0"})).
+				To(Equal("registry.local:5000/backplane/json-server:1.0"))
+			Expect(resolveImage(def, &examplev1.ImageSpec{Repository: "mirror/json-server"})).
+				To(Equal("mirror/json-server"))
+			Expect(resolveImage(def, &examplev1.ImageSpec{Repository: "mirror/json-server", Tag: "1.0"})).
+				To(Equal("mirror/json-server:1.0"))
+			Expect(resolveImage(def, &examplev1.ImageSpec{Tag: "1.0", Digest: digest})).
+				To(Equal("registry.local:5000/backplane/json-server@" + digest))
+		})
+
+		It("should pick the pull policy for the resolved image", func() {
+			Expect(imagePullPolicy("backplane/json-server", nil)).To(Equal(corev1.PullAlways))
+			Expect(imagePullPolicy("backplane/json-server:latest", nil)).To(Equal(corev1.PullAlways))
+			Expect(imagePullPolicy("backplane/json-server:0.17", nil)).To(Equal(corev1.PullIfNotPresent))
+			Expect(imagePullPolicy("backplane/json-server@sha256:"+strings.Repeat("a", 64), nil)).
+				To(Equal(corev1.PullIfNotPresent))
+			Expect(imagePullPolicy("backplane/json-server:0.17", &examplev1.ImageSpec{PullPolicy: corev1.PullNever})).
+				To(Equal(corev1.PullNever))
+		})
+
+		It("should follow a changed operator default on every reconcile", func() {
+			js := &examplev1.JsonServer{
+				ObjectMeta: metav1.ObjectMeta{Name: "app-image", Namespace: "default"},
+				Spec:       examplev1.JsonServerSpec{JsonConfig: `{}`},
+			}
+			examplev1.SetDefaults(js)
+			Expect(js.Spec.Image).To(BeNil())
+
+			server := (&JsonServerReconciler{DefaultImage: "mirror/json-server:0.17"}).
+				desiredDeployment(js, 1).Spec.Template.Spec.Containers[0]
+			Expect(server.Image).To(Equal("mirror/json-server:0.17"))
+			Expect(server.ImagePullPolicy).To(Equal(corev1.PullIfNotPresent))
+
+			server = (&JsonServerReconciler{DefaultImage: "mirror/json-server:0.18"}).
+				desiredDeployment(js, 1).Spec.Template.Spec.Containers[0]
+			Expect(server.Image).To(Equal("mirror/json-server:0.18"))
+		})
+	})
+
+	Context("When rendering server options", func() {
+		It("should write routes and middlewares next to db.json and pass them as args", func() {
+			delay := int32(500)
+			js := &examplev1.JsonServer{
+				Spec: examplev1.JsonServerSpec{
+					JsonConfig: `{}`,
+					Server: &examplev1.ServerSpec{
+						Routes:           map[string]string{"/api/*": "/$1"},
+						Middlewares:      map[string]string{"b.js": "b", "a.js": "a"},
+						DelayMs:          &delay,
+						ReadOnly:         true,
+						IDField:          "_id",
+						ForeignKeySuffix: "_id",
+					},
+				},
+			}
+
+			Expect(configMapData(js)).To(Equal(map[string]string{
+				"db.json":     `{}`,
+				"routes.json": `{"/api/*":"/$1"}`,
+				"a.js":        "a",
+				"b.js":        "b",
+			}))
+			Expect(serverArgs(js)).To(Equal([]string{
+				"/data/db.json",
+				"--port", "3000",
+				"--routes", "/data/routes.json",
+				"--middlewares", "/data/a.js",
+				"--middlewares", "/data/b.js",
+				"--delay", "500",
+				"--read-only",
+				"--id", "_id",
+				"--foreignKeySuffix", "_id",
+			}))
+		})
+
+		It("should listen on spec.port and keep the Service port", func() {
+			js := &examplev1.JsonServer{
+				ObjectMeta: metav1.ObjectMeta{Name: "app-port", Namespace: "default"},
+				Spec:       examplev1.JsonServerSpec{JsonConfig: `{}`, Port: 8080},
+			}
+			examplev1.SetDefaults(js)
+
+			deploy := (&JsonServerReconciler{}).desiredDeployment(js, 1)
+			server := deploy.Spec.Template.Spec.Containers[0]
+			Expect(server.Args).To(ContainElements("--port", "8080"))
+			Expect(server.Ports[0].ContainerPort).To(Equal(int32(8080)))
+			Expect(server.Image).To(Equal(examplev1.DefaultImage))
+			Expect(serviceURL(js)).To(Equal("http://app-port.default.svc:3000"))
+		})
+	})
+
+	Context("When rendering a large jsonConfig", func() {
+		It("should store db.json compressed and unpack it in an init container", func() {
+			item := `{"name":"Alice","email":"alice@example.com","active":1},`
+			config := `{"people":[` + strings.Repeat(item, 22000) + `{"id":0}]}`
+			js := &examplev1.JsonServer{
+				ObjectMeta: metav1.ObjectMeta{Name: "app-large", Namespace: "default"},
+				Spec:       examplev1.JsonServerSpec{JsonConfig: config},
+			}
+
+			data, binaryData, err := renderConfigMap(js)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(data).NotTo(HaveKey("db.json"))
+			Expect(binaryData).To(HaveKey("db.json.gz"))
+
+			zr, err := gzip.NewReader(bytes.NewReader(binaryData["db.json.gz"]))
+			Expect(err).NotTo(HaveOccurred())
+			unpacked, err := io.ReadAll(zr)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(unpacked)).To(Equal(config))
+
+			pod := (&JsonServerReconciler{}).desiredDeployment(js, 1).Spec.Template.Spec
+			Expect(pod.InitContainers).To(HaveLen(1))
+			Expect(pod.Containers[0].VolumeMounts).To(ConsistOf(
+				corev1.VolumeMount{Name: "data", MountPath: "/data"}))
+		})
+	})
+
+	Context("When rendering the config hash", func() {
+		It("should change with any rendered file", func() {
+			base := map[string]string{"db.json": `{}`}
+			Expect(configHash(base)).To(Equal(configHash(map[string]string{"db.json": `{}`})))
+			Expect(configHash(base)).NotTo(Equal(configHash(map[string]string{"db.json": `{"a":[]}`})))
+			Expect(configHash(base)).NotTo(Equal(configHash(map[string]string{"db.json": `{}`, "a.js": ""})))
+		})
+	})
+
+	Context("When rendering the Service", func() {
+		It("should apply spec.service", func() {
+			js := &examplev1.JsonServer{
+				ObjectMeta: metav1.ObjectMeta{Name: "app-svc", Namespace: "default"},
+				Spec: examplev1.JsonServerSpec{
+					JsonConfig: `{}`,
+					Port:       8080,
+					Service: &examplev1.ServiceSpec{
+						Type:        corev1.ServiceTypeNodePort,
+						Port:        80,
+						NodePort:    30080,
+						Annotations: map[string]string{"example.com/team": "qa"},
+						Labels:      map[string]string{"tier": "mock"},
+					},
+				},
+			}
+
+			svc := desiredService(js)
+			Expect(svc.Spec.Type).To(Equal(corev1.ServiceTypeNodePort))
+			Expect(svc.Spec.Selector).To(Equal(map[string]string{"app": "app-svc"}))
+			Expect(svc.Spec.Ports).To(ConsistOf(corev1.ServicePort{
+				Name:       "http",
+				Protocol:   corev1.ProtocolTCP,
+				Port:       80,
+				NodePort:   30080,
+				TargetPort: intstr.FromInt32(8080),
+			}))
+			Expect(svc.Annotations).To(HaveKeyWithValue("example.com/team", "qa"))
+			Expect(svc.Labels).To(HaveKeyWithValue("tier", "mock"))
+			Expect(serviceURL(js)).To(Equal("http://app-svc.default.svc:80"))
+		})
+	})
+
+	Context("When rendering external access", func() {
+		newExposed := func(ingress *examplev1.IngressSpec) *examplev1.JsonServer {
+			return &examplev1.JsonServer{
+				ObjectMeta: metav1.ObjectMeta{Name: "app-exposed", Namespace: "default"},
+				Spec:       examplev1.JsonServerSpec{JsonConfig: `{}`, Ingress: ingress},
+			}
+		}
+
+		It("should route the host and path to the Service through an Ingress", func() {
+			className := "nginx"
+			js := newExposed(&examplev1.IngressSpec{
+				Host:          "people.mock.example.com",
+				Path:          "/api",
+				ClassName:     &className,
+				TLSSecretName: "mock-tls",
+			})
+
+			ing := desiredIngress(js)
+			Expect(ing.Spec.IngressClassName).To(Equal(&className))
+			Expect(ing.Spec.TLS).To(ConsistOf(networkingv1.IngressTLS{
+				Hosts:      []string{"people.mock.example.com"},
+				SecretName: "mock-tls",
+			}))
+			path := ing.Spec.Rules[0].HTTP.Paths[0]
+			Expect(path.Path).To(Equal("/api"))
+			Expect(path.Backend.Service.Name).To(Equal("app-exposed"))
+			Expect(path.Backend.Service.Port.Number).To(Equal(int32(3000)))
+			Expect(publicURL(js.Spec.Ingress)).To(Equal("https://people.mock.example.com/api"))
+		})
+
+		It("should attach an HTTPRoute to the referenced Gateway", func() {
+			js := newExposed(&examplev1.IngressSpec{
+				Host:    "people.mock.example.com",
+				Gateway: &examplev1.GatewayReference{Name: "public", Namespace: "gateways"},
+			})
+
+			route := desiredHTTPRoute(js)
+			Expect(route.GroupVersionKind()).To(Equal(httpRouteGVK))
+			parents, _, _ := unstructured.NestedSlice(route.Object, "spec", "parentRefs")
+			Expect(parents).To(ConsistOf(HaveKeyWithValue("namespace", "gateways")))
+			hostnames, _, _ := unstructured.NestedStringSlice(route.Object, "spec", "hostnames")
+			Expect(hostnames).To(Equal([]string{"people.mock.example.com"}))
+			Expect(publicURL(js.Spec.Ingress)).To(Equal("http://people.mock.example.com"))
+		})
+	})
+
+	Context("When reconciling a JsonServer with persistent storage", func() {
+		const resourceName = "app-storage"
+
+		ctx := context.Background()
+		namespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+
+		BeforeEach(func() {
+			By("Creating a JsonServer resource with spec.storage")
+			js := &examplev1.JsonServer{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resourceName,
+					Namespace: "default",
+				},
+				Spec: examplev1.JsonServerSpec{
+					JsonConfig: `{"people": []}`,
+					Storage: &examplev1.StorageSpec{
+						ReseedPolicy: examplev1.ReseedNever,
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, js)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			js := &examplev1.JsonServer{}
+			if err := k8sClient.Get(ctx, namespacedName, js); err == nil {
+				Expect(k8sClient.Delete(ctx, js)).To(Succeed())
+			}
+		})
+
+		It("should create a PVC and seed it with an init container", func() {
+			By("Waiting for the PersistentVolumeClaim to be created")
+			Eventually(func(g Gomega) {
+				pvc := &corev1.PersistentVolumeClaim{}
+				g.Expect(k8sClient.Get(ctx, namespacedName, pvc)).To(Succeed())
+				g.Expect(pvc.Spec.Resources.Requests.Storage().String()).To(Equal("1Gi"))
+			}).Should(Succeed())
+
+			By("Checking the Deployment mounts the claim and seeds it")
+			Eventually(func(g Gomega) {
+				deploy := &appsv1.Deployment{}
+				g.Expect(k8sClient.Get(ctx, namespacedName, deploy)).To(Succeed())
+				g.Expect(deploy.Spec.Strategy.Type).To(Equal(appsv1.RecreateDeploymentStrategyType))
+
+				pod := deploy.Spec.Template.Spec
+				g.Expect(pod.InitContainers).To(HaveLen(1))
+				g.Expect(pod.InitContainers[0].Env).To(ContainElement(
+					corev1.EnvVar{Name: "RESEED_POLICY", Value: "Never"}))
+				g.Expect(pod.Containers[0].VolumeMounts).To(ConsistOf(
+					corev1.VolumeMount{Name: "data", MountPath: "/data"}))
+			}).Should(Succeed())
+		})
+	})
+
+	Context("When deleting a JsonServer", func() {
+		const resourceName = "app-retain"
+
+		ctx := context.Background()
+		namespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+
+		It("should keep the claim and Service with deletionPolicy Retain", func() {
+			By("Creating a JsonServer with storage and deletionPolicy Retain")
+			js := &examplev1.JsonServer{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resourceName,
+					Namespace: "default",
+				},
+				Spec: examplev1.JsonServerSpec{
+					JsonConfig:     `{"people": []}`,
+					Storage:        &examplev1.StorageSpec{},
+					DeletionPolicy: examplev1.DeletionPolicyRetain,
+				},
+			}
+			Expect(k8sClient.Create(ctx, js)).To(Succeed())
+			DeferCleanup(func() {
+				_ = k8sClient.Delete(ctx, &corev1.PersistentVolumeClaim{ObjectMeta: js.ObjectMeta})
+				_ = k8sClient.Delete(ctx, &corev1.Service{ObjectMeta: js.ObjectMeta})
+			})
+
+			By("Waiting for the finalizer, the claim and the Service")
+			Eventually(func(g Gomega) {
+				js := &examplev1.JsonServer{}
+				g.Expect(k8sClient.Get(ctx, namespacedName, js)).To(Succeed())
+				g.Expect(js.Finalizers).To(ContainElement(examplev1.Finalizer))
+				g.Expect(k8sClient.Get(ctx, namespacedName, &corev1.PersistentVolumeClaim{})).To(Succeed())
+				g.Expect(k8sClient.Get(ctx, namespacedName, &corev1.Service{})).To(Succeed())
+			}).Should(Succeed())
+
+			By("Deleting the JsonServer")
+			Expect(k8sClient.Delete(ctx, js)).To(Succeed())
+			Eventually(func() bool {
+				return errors.IsNotFound(k8sClient.Get(ctx, namespacedName, &examplev1.JsonServer{}))
+			}).Should(BeTrue())
+
+			By("Checking the claim and the Service are no longer owned")
+			pvc := &corev1.PersistentVolumeClaim{}
+			Expect(k8sClient.Get(ctx, namespacedName, pvc)).To(Succeed())
+			Expect(pvc.OwnerReferences).To(BeEmpty())
+			svc := &corev1.Service{}
+			Expect(k8sClient.Get(ctx, namespacedName, svc)).To(Succeed())
+			Expect(svc.OwnerReferences).To(BeEmpty())
+		})
+	})
+
+	Context("When referencing data in a ConfigMap", func() {
+		const resourceName = "app-datafrom"
+
+		ctx := context.Background()
+		namespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+
+		AfterEach(func() {
+			js := &examplev1.JsonServer{}
+			if err := k8sClient.Get(ctx, namespacedName, js); err == nil {
+				Expect(k8sClient.Delete(ctx, js)).To(Succeed())
+			}
+		})
+
+		It("should render the referenced key and follow its changes", func() {
+			By("Creating the fixtures ConfigMap")
+			fixtures := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName + "-fixtures", Namespace: "default"},
+				Data:       map[string]string{"people.json": `{"people":[]}`},
+			}
+			Expect(k8sClient.Create(ctx, fixtures)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, fixtures)).To(Succeed())
+			})
+
+			By("Creating a JsonServer that reads it")
+			js := &examplev1.JsonServer{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+				Spec: examplev1.JsonServerSpec{
+					DataFrom: &examplev1.DataSource{
+						ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+							LocalObjectReference: corev1.LocalObjectReference{Name: fixtures.Name},
+							Key:                  "people.json",
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, js)).To(Succeed())
+
+			Eventually(func(g Gomega) {
+				cm := &corev1.ConfigMap{}
+				g.Expect(k8sClient.Get(ctx, namespacedName, cm)).To(Succeed())
+				g.Expect(cm.Data).To(HaveKeyWithValue("db.json", `{"people":[]}`))
+			}).Should(Succeed())
+
+			By("Changing the referenced key")
+			fixtures.Data["people.json"] = `{"people":[{"id":1}]}`
+			Expect(k8sClient.Update(ctx, fixtures)).To(Succeed())
+
+			Eventually(func(g Gomega) {
+				cm := &corev1.ConfigMap{}
+				g.Expect(k8sClient.Get(ctx, namespacedName, cm)).To(Succeed())
+				g.Expect(cm.Data).To(HaveKeyWithValue("db.json", `{"people":[{"id":1}]}`))
+			}).Should(Succeed())
+		})
+	})
+
+	Context("When referencing data in YAML", func() {
+		const resourceName = "app-yaml"
+
+		ctx := context.Background()
+		namespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+
+		AfterEach(func() {
+			js := &examplev1.JsonServer{}
+			if err := k8sClient.Get(ctx, namespacedName, js); err == nil {
+				Expect(k8sClient.Delete(ctx, js)).To(Succeed())
+			}
+		})
+
+		It("should serve it as JSON and report syntax errors", func() {
+			By("Creating the fixtures ConfigMap")
+			fixtures := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName + "-fixtures", Namespace: "default"},
+				Data:       map[string]string{"db.yaml": "people:\n  - id: 1\n    name: Alice\n"},
+			}
+			Expect(k8sClient.Create(ctx, fixtures)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, fixtures)).To(Succeed())
+			})
+
+			By("Creating a JsonServer that reads it")
+			js := &examplev1.JsonServer{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+				Spec: examplev1.JsonServerSpec{
+					DataFormat: examplev1.DataFormatYAML,
+					DataFrom: &examplev1.DataSource{
+						ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+							LocalObjectReference: corev1.LocalObjectReference{Name: fixtures.Name},
+							Key:                  "db.yaml",
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, js)).To(Succeed())
+
+			Eventually(func(g Gomega) {
+				cm := &corev1.ConfigMap{}
+				g.Expect(k8sClient.Get(ctx, namespacedName, cm)).To(Succeed())
+				g.Expect(cm.Data).To(HaveKeyWithValue("db.json", `{"people":[{"id":1,"name":"Alice"}]}`))
+			}).Should(Succeed())
+
+			By("Breaking the referenced key")
+			fixtures.Data["db.yaml"] = "people: [\n"
+			Expect(k8sClient.Update(ctx, fixtures)).To(Succeed())
+
+			Eventually(func(g Gomega) {
+				js := &examplev1.JsonServer{}
+				g.Expect(k8sClient.Get(ctx, namespacedName, js)).To(Succeed())
+				cond := meta.FindStatusCondition(js.Status.Conditions, examplev1.ConditionConfigValid)
+				g.Expect(cond).NotTo(BeNil())
+				g.Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+				g.Expect(cond.Message).To(HavePrefix(
+					`Error: key "db.yaml" of ConfigMap app-yaml-fixtures is not valid yaml: line 1: `))
+			}).Should(Succeed())
+		})
+	})
+
+	Context("When reading the data format", func() {
+		It("should treat data generated from OpenAPI as JSON", func() {
+			js := &examplev1.JsonServer{Spec: examplev1.JsonServerSpec{DataFormat: examplev1.DataFormatJSONC}}
+			Expect(dataFormat(js)).To(Equal(examplev1.DataFormatJSONC))
+
+			js.Spec.OpenAPI = &examplev1.OpenAPISource{}
+			Expect(dataFormat(js)).To(Equal(examplev1.DataFormatJSON))
+		})
+
+		It("should decode every format to the same JSON", func() {
+			documents := map[examplev1.DataFormat]string{
+				examplev1.DataFormatJSON:  `{"people": [{"id": 1, "name": "Alice"}], "profile": {}}`,
+				examplev1.DataFormatJSONC: "{\n  // seed\n  \"people\": [{\"id\": 1, \"name\": \"Alice\"},],\n  \"profile\": {},\n}",
+				examplev1.DataFormatJSON5: "{people: [{id: 1, name: 'Alice'}], profile: {},}",
+				examplev1.DataFormatYAML:  "people:\n  - id: 1\n    name: Alice\nprofile: {}\n",
+			}
+			for format, document := range documents {
+				data, err := dataformat.Decode([]byte(document), format)
+				Expect(err).NotTo(HaveOccurred(), string(format))
+				Expect(string(data)).To(MatchJSON(`{"people":[{"id":1,"name":"Alice"}],"profile":{}}`), string(format))
+			}
+		})
+	})
+
+	Context("When rejecting a snapshot promotion", func() {
+		It("should refuse composed instances and report it in the status without retrying", func() {
+			scheme := runtime.NewScheme()
+			Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+			Expect(examplev1.AddToScheme(scheme)).To(Succeed())
+
+			snapshot := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "app-composed-snapshot-test",
+					Namespace: "default",
+					Labels:    map[string]string{examplev1.InstanceLabel: "app-composed"},
+				},
+				Data: map[string]string{"db.json": `{"people":[],"posts":[]}`},
+			}
+			js := &examplev1.JsonServer{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "app-composed",
+					Namespace:   "default",
+					Annotations: map[string]string{examplev1.PromoteSnapshotAnnotation: snapshot.Name},
+				},
+				Spec: examplev1.JsonServerSpec{
+					JsonConfig:  `{"people":[]}`,
+					Collections: []examplev1.CollectionSource{{Name: "posts", Inline: `[]`}},
+				},
+			}
+			c := fake.NewClientBuilder().WithScheme(scheme).
+				WithObjects(js, snapshot).WithStatusSubresource(js).Build()
+			recorder := events.NewFakeRecorder(10)
+			r := &JsonServerReconciler{Client: c, Scheme: scheme, Recorder: recorder}
+
+			result, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(js)})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal(reconcile.Result{}))
+
+			stored := &examplev1.JsonServer{}
+			Expect(c.Get(ctx, client.ObjectKeyFromObject(js), stored)).To(Succeed())
+			Expect(stored.Spec.JsonConfig).To(Equal(`{"people":[]}`))
+			Expect(stored.Annotations).To(HaveKey(examplev1.PromoteSnapshotAnnotation))
+			message := "Error: promoting snapshot app-composed-snapshot-test failed: spec.collections or " +
+				"spec.generate is set, remove them to serve the snapshot: invalid snapshot promotion"
+			Expect(stored.Status.LastSnapshot.Message).To(Equal(message))
+			valid := meta.FindStatusCondition(stored.Status.Conditions, examplev1.ConditionConfigValid)
+			Expect(valid).NotTo(BeNil())
+			Expect(valid.Status).To(Equal(metav1.ConditionFalse))
+			Expect(valid.Reason).To(Equal(examplev1.ReasonInvalidConfig))
+			Expect(valid.Message).To(Equal(message))
+			Expect(stored.Status.State).To(Equal(examplev1.StateError))
+			Expect(recorder.Events).To(Receive(ContainSubstring(examplev1.ReasonInvalidConfig)))
+
+			By("promoting it once the collections are removed")
+			stored.Spec.Collections = nil
+			promoted, err := r.promoteSnapshot(ctx, stored)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(promoted).To(BeTrue())
+			Expect(c.Get(ctx, client.ObjectKeyFromObject(js), stored)).To(Succeed())
+			Expect(stored.Spec.JsonConfig).To(Equal(`{"people":[],"posts":[]}`))
+		})
+
+		DescribeTable("should not retry snapshots that cannot be promoted",
+			func(name string, labels, data map[string]string, want string) {
+				scheme := runtime.NewScheme()
+				Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+				Expect(examplev1.AddToScheme(scheme)).To(Succeed())
+
+				other := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "default", Labels: labels},
+					Data:       data,
+				}
+				js := &examplev1.JsonServer{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:        "app-promote",
+						Namespace:   "default",
+						Annotations: map[string]string{examplev1.PromoteSnapshotAnnotation: name},
+					},
+					Spec: examplev1.JsonServerSpec{JsonConfig: `{}`},
+				}
+				c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(js, other).Build()
+				r := &JsonServerReconciler{Client: c, Scheme: scheme}
+
+				_, err := r.promoteSnapshot(ctx, js)
+				Expect(err).To(MatchError(errInvalidPromotion))
+				Expect(err).To(MatchError(ContainSubstring(want)))
+			},
+			Entry("a missing ConfigMap", "missing", nil, nil, "ConfigMap missing not found"),
+			Entry("a ConfigMap of another instance", "other",
+				map[string]string{examplev1.InstanceLabel: "app-other"}, map[string]string{"db.json": `{}`},
+				"ConfigMap other is not a snapshot of app-promote"),
+			Entry("a ConfigMap without db.json", "other",
+				map[string]string{examplev1.InstanceLabel: "app-promote"}, map[string]string{"data.json": `{}`},
+				"ConfigMap other has no db.json key"),
+		)
+	})
+
+	Context("When recording events", func() {
+		newRecorded := func(funcs interceptor.Funcs) (*JsonServerReconciler, *events.FakeRecorder, *examplev1.JsonServer) {
+			scheme := runtime.NewScheme()
+			Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+			Expect(examplev1.AddToScheme(scheme)).To(Succeed())
+
+			js := &examplev1.JsonServer{
+				ObjectMeta: metav1.ObjectMeta{Name: "app-events", Namespace: "default", UID: "uid-1"},
+				Spec:       examplev1.JsonServerSpec{JsonConfig: `{}`},
+			}
+			c := fake.NewClientBuilder().WithScheme(scheme).
+				WithObjects(js).WithStatusSubresource(js).
+				WithInterceptorFuncs(funcs).Build()
+			recorder := events.NewFakeRecorder(10)
+			return &JsonServerReconciler{Client: c, Scheme: scheme, Recorder: recorder}, recorder, js
+		}
+
+		It("should report created and updated children", func() {
+			r, recorder, js := newRecorded(interceptor.Funcs{})
+
+			Expect(r.reconcileConfigMap(context.Background(), js, map[string]string{"db.json": `{}`}, nil)).To(Succeed())
+			Expect(recorder.Events).To(Receive(Equal("Normal Created Created ConfigMap app-events")))
+
+			Expect(r.reconcileConfigMap(context.Background(), js, map[string]string{"db.json": `{}`}, nil)).To(Succeed())
+			Expect(recorder.Events).NotTo(Receive())
+
+			Expect(r.reconcileConfigMap(context.Background(), js, map[string]string{"db.json": `{"people":[]}`}, nil)).To(Succeed())
+			Expect(recorder.Events).To(Receive(Equal("Normal Updated Updated ConfigMap app-events")))
+
+			examplev1.SetDefaults(js)
+			deploy, err := r.reconcileDeployment(context.Background(), js)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(recorder.Events).To(Receive(Equal("Normal Created Created Deployment app-events")))
+			Expect(recorder.Events).To(Receive(Equal(fmt.Sprintf("Normal RolloutStarted Deployment app-events started rolling out config %s",
+				deploy.Spec.Template.Annotations[examplev1.ConfigHashAnnotation]))))
+		})
+
+		It("should report invalid config and failed status updates", func() {
+			r, recorder, js := newRecorded(interceptor.Funcs{
+				SubResourceUpdate: func(context.Context, client.Client, string, client.Object, ...client.SubResourceUpdateOption) error {
+					return errors.NewServiceUnavailable("etcd is unavailable")
+				},
+			})
+
+			r.invalidConfig(js, "Error: spec.jsonConfig is not valid json")
+			err := r.updateStatus(context.Background(), js)
+			Expect(errors.IsServiceUnavailable(err)).To(BeTrue())
+			Expect(recorder.Events).To(Receive(Equal("Warning InvalidConfig Error: spec.jsonConfig is not valid json")))
+			Expect(recorder.Events).To(Receive(Equal("Warning StatusUpdateFailed Failed to update status: etcd is unavailable")))
+		})
+
+		It("should report failed status updates of the deletion snapshot", func() {
+			conflict := true
+			r, recorder, js := newRecorded(interceptor.Funcs{
+				SubResourceUpdate: func(context.Context, client.Client, string, client.Object, ...client.SubResourceUpdateOption) error {
+					if conflict {
+						return errors.NewConflict(examplev1.GroupVersion.WithResource("jsonservers").GroupResource(),
+							"app-events", fmt.Errorf("the object has been modified"))
+					}
+					return errors.NewServiceUnavailable("etcd is unavailable")
+				},
+			})
+			js.Spec.DataFrom = &examplev1.DataSource{SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "seed"},
+				Key:                  "db.json",
+			}}
+
+			err := r.deletionSnapshot(context.Background(), js)
+			Expect(err).To(MatchError("the data comes from Secret seed and is not copied into a ConfigMap"))
+			Expect(recorder.Events).NotTo(Receive())
+
+			By("reporting other failures")
+			conflict = false
+			err = r.deletionSnapshot(context.Background(), js)
+			Expect(errors.IsServiceUnavailable(err)).To(BeTrue())
+			Expect(recorder.Events).To(Receive(Equal("Warning StatusUpdateFailed Failed to update status: etcd is unavailable")))
+		})
+
+		It("should report invalid config only when ConfigValid turns False", func() {
+			r, recorder, js := newRecorded(interceptor.Funcs{})
+
+			r.invalidConfig(js, "Error: spec.jsonConfig is not valid json")
+			Expect(recorder.Events).To(Receive(Equal("Warning InvalidConfig Error: spec.jsonConfig is not valid json")))
+
+			By("staying invalid on the next reconciles")
+			r.invalidConfig(js, "Error: spec.jsonConfig is not valid json")
+			r.invalidConfig(js, "Error: spec.jsonConfig is not valid yaml")
+			Expect(recorder.Events).NotTo(Receive())
+
+			By("turning invalid again after it was valid")
+			setCondition(js, examplev1.ConditionConfigValid, metav1.ConditionTrue, examplev1.ReasonValid, "valid")
+			r.invalidConfig(js, "Error: spec.jsonConfig is not valid json")
+			Expect(recorder.Events).To(Receive(Equal("Warning InvalidConfig Error: spec.jsonConfig is not valid json")))
+
+			By("turning invalid after a failed reconcile")
+			setCondition(js, examplev1.ConditionConfigValid, metav1.ConditionFalse, examplev1.ReasonReconcileFailed, "Error: unexpected failure")
+			r.invalidConfig(js, "Error: spec.jsonConfig is not valid json")
+			Expect(recorder.Events).To(Receive(Equal("Warning InvalidConfig Error: spec.jsonConfig is not valid json")))
+		})
+
+		It("should ignore status conflicts", func() {
+			r, recorder, js := newRecorded(interceptor.Funcs{
+				SubResourceUpdate: func(context.Context, client.Client, string, client.Object, ...client.SubResourceUpdateOption) error {
+					return errors.NewConflict(examplev1.GroupVersion.WithResource("jsonservers").GroupResource(), "app-events", nil)
+				},
+			})
+
+			Expect(r.updateStatus(context.Background(), js)).To(Succeed())
+			Expect(recorder.Events).NotTo(Receive())
+		})
+	})
+
+	Context("When recording metrics", func() {
+		newMetered := func(funcs interceptor.Funcs) (*JsonServerReconciler, *examplev1.JsonServer) {
+			scheme := runtime.NewScheme()
+			Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+			Expect(examplev1.AddToScheme(scheme)).To(Succeed())
+
+			js := &examplev1.JsonServer{
+				ObjectMeta: metav1.ObjectMeta{Name: "app-metrics", Namespace: "default", UID: "uid-2"},
+				Spec:       examplev1.JsonServerSpec{JsonConfig: `{"people":[{"id":1},{"id":2},{"id":3}],"profile":{}}`},
+			}
+			c := fake.NewClientBuilder().WithScheme(scheme).
+				WithObjects(js).WithStatusSubresource(js).
+				WithInterceptorFuncs(funcs).Build()
+			return &JsonServerReconciler{Client: c, Scheme: scheme}, js
+		}
+
+		It("should report the state, size and collections of each instance", func() {
+			r, js := newMetered(interceptor.Funcs{})
+			key := types.NamespacedName{Name: js.Name, Namespace: js.Namespace}
+			synced := testutil.ToFloat64(instancesByState.WithLabelValues(examplev1.StateSynced))
+			failed := testutil.ToFloat64(instancesByState.WithLabelValues(examplev1.StateError))
+
+			for _, t := range []string{
+				examplev1.ConditionConfigValid,
+				examplev1.ConditionDeploymentAvailable,
+				examplev1.ConditionServiceReady,
+			} {
+				setCondition(js, t, metav1.ConditionTrue, examplev1.ReasonAvailable, "ok")
+			}
+			js.Status.Collections = []examplev1.CollectionStatus{
+				{Name: "people", Source: "spec.jsonConfig", Items: 3},
+				{Name: "profile", Source: "spec.jsonConfig", Items: 1},
+			}
+			recordConfigSize(js)
+			Expect(r.updateStatus(context.Background(), js)).To(Succeed())
+
+			Expect(testutil.ToFloat64(instancesByState.WithLabelValues(examplev1.StateSynced))).To(Equal(synced + 1))
+			Expect(testutil.ToFloat64(configSizeBytes.With(prometheus.Labels{
+				"jsonserver_namespace": "default",
+				"jsonserver_name":      "app-metrics",
+			}))).To(BeNumerically("==", len(js.Spec.JsonConfig)))
+			Expect(testutil.ToFloat64(collectionCount.WithLabelValues("default", "app-metrics"))).To(Equal(2.0))
+			Expect(testutil.ToFloat64(itemCount.WithLabelValues("default", "app-metrics"))).To(Equal(4.0))
+			lastSync := testutil.ToFloat64(lastSyncTimestamp.WithLabelValues("default", "app-metrics"))
+			Expect(lastSync).To(BeNumerically(">", 0))
+
+			By("moving the instance to Error without a new sync")
+			setCondition(js, examplev1.ConditionConfigValid, metav1.ConditionFalse,
+				examplev1.ReasonInvalidConfig, "Error: spec.jsonConfig is not valid json")
+			Expect(r.updateStatus(context.Background(), js)).To(Succeed())
+			Expect(testutil.ToFloat64(instancesByState.WithLabelValues(examplev1.StateSynced))).To(Equal(synced))
+			Expect(testutil.ToFloat64(instancesByState.WithLabelValues(examplev1.StateError))).To(Equal(failed + 1))
+			Expect(testutil.ToFloat64(lastSyncTimestamp.WithLabelValues("default", "app-metrics"))).To(Equal(lastSync))
+
+			By("forgetting a deleted instance")
+			forgetMetrics(key)
+			Expect(testutil.ToFloat64(instancesByState.WithLabelValues(examplev1.StateError))).To(Equal(failed))
+			Expect(configSizeBytes.DeleteLabelValues("default", "app-metrics")).To(BeFalse())
+			Expect(lastSyncTimestamp.DeleteLabelValues("default", "app-metrics")).To(BeFalse())
+		})
+
+		It("should count reconcile errors by phase", func() {
+			r, js := newMetered(interceptor.Funcs{
+				Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+					if _, ok := obj.(*corev1.ConfigMap); ok {
+						return errors.NewServiceUnavailable("etcd is unavailable")
+					}
+					return c.Create(ctx, obj, opts...)
+				},
+			})
+			failures := testutil.ToFloat64(reconcileErrors.WithLabelValues(phaseConfigMap))
+
+			_, err := r.Reconcile(context.Background(), reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: js.Name, Namespace: js.Namespace},
+			})
+			Expect(errors.IsServiceUnavailable(err)).To(BeTrue())
+			Expect(testutil.ToFloat64(reconcileErrors.WithLabelValues(phaseConfigMap))).To(Equal(failures + 1))
+		})
+	})
+
+	Context("When reading data from Secrets", func() {
+		newSecretBacked := func(secret *corev1.Secret) (*JsonServerReconciler, *examplev1.JsonServer) {
+			scheme := runtime.NewScheme()
+			Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+			Expect(examplev1.AddToScheme(scheme)).To(Succeed())
+
+			js := &examplev1.JsonServer{
+				ObjectMeta: metav1.ObjectMeta{Name: "app-secret", Namespace: "default", UID: "uid-3"},
+				Spec: examplev1.JsonServerSpec{
+					DataFrom: &examplev1.DataSource{
+						SecretKeyRef: &corev1.SecretKeySelector{
+							LocalObjectReference: corev1.LocalObjectReference{Name: secret.Name},
+							Key:                  "db.json",
+						},
+					},
+				},
+			}
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(js, secret).Build()
+			return &JsonServerReconciler{Client: c, Scheme: scheme}, js
+		}
+		fixtures := func(labels map[string]string) *corev1.Secret {
+			return &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "fixtures", Namespace: "default", Labels: labels},
+				Data:       map[string][]byte{"db.json": []byte(`{"people":[]}`)},
+			}
+		}
+
+		It("should only read Secrets labelled as data sources", func() {
+			r, js := newSecretBacked(fixtures(nil))
+			err := r.resolveDataFrom(context.Background(), js)
+			Expect(err).To(MatchError(errSecretNotAllowed))
+			Expect(err.Error()).To(Equal("Error: Secret fixtures is not labelled json-server.example.com/data-source=true"))
+
+			r, js = newSecretBacked(fixtures(map[string]string{examplev1.DataSourceLabel: "true"}))
+			Expect(r.resolveDataFrom(context.Background(), js)).To(Succeed())
+			Expect(js.Spec.JsonConfig).To(Equal(`{"people":[]}`))
+		})
+
+		It("should render the data into a Secret instead of the ConfigMap", func() {
+			r, js := newSecretBacked(fixtures(map[string]string{examplev1.DataSourceLabel: "true"}))
+			ctx := context.Background()
+			key := types.NamespacedName{Name: js.Name, Namespace: js.Namespace}
+
+			By("replacing the ConfigMap of an instance switched to a Secret")
+			plain := js.DeepCopy()
+			plain.Spec.DataFrom = nil
+			Expect(r.reconcileConfig(ctx, plain, map[string]string{"db.json": `{}`}, nil)).To(Succeed())
+			Expect(r.Get(ctx, key, &corev1.ConfigMap{})).To(Succeed())
+
+			Expect(r.reconcileConfig(ctx, js, map[string]string{"db.json": `{"people":[]}`}, nil)).To(Succeed())
+			secret := &corev1.Secret{}
+			Expect(r.Get(ctx, key, secret)).To(Succeed())
+			Expect(secret.Data).To(HaveKeyWithValue("db.json", []byte(`{"people":[]}`)))
+			Expect(metav1.IsControlledBy(secret, js)).To(BeTrue())
+			Expect(errors.IsNotFound(r.Get(ctx, key, &corev1.ConfigMap{}))).To(BeTrue())
+			Expect(configVolumeSource(js).Secret.SecretName).To(Equal(js.Name))
+
+			By("refusing to copy the data into a snapshot ConfigMap")
+			_, err := r.takeSnapshot(ctx, js)
+			Expect(err).To(MatchError(ContainSubstring("not copied into a ConfigMap")))
+
+			By("going back to a ConfigMap")
+			Expect(r.reconcileConfig(ctx, plain, map[string]string{"db.json": `{}`}, nil)).To(Succeed())
+			Expect(errors.IsNotFound(r.Get(ctx, key, &corev1.Secret{}))).To(BeTrue())
+			Expect(configVolumeSource(plain).ConfigMap.Name).To(Equal(js.Name))
+		})
+	})
+
+	Context("When indexing data sources", func() {
+		It("should index JsonServers by the objects they read", func() {
+			js := &examplev1.JsonServer{
+				Spec: examplev1.JsonServerSpec{
+					DataFrom: &examplev1.DataSource{
+						SecretKeyRef: &corev1.SecretKeySelector{
+							LocalObjectReference: corev1.LocalObjectReference{Name: "fixtures"},
+							Key:                  "db.json",
+						},
+					},
+				},
+			}
+			Expect(dataSources(js)).To(Equal([]string{"Secret/fixtures"}))
+			Expect(dataSourceName(js)).To(Equal(`key "db.json" of Secret fixtures`))
+		})
+	})
+
+	Context("When composing collections", func() {
+		newComposed := func(config string, collections ...examplev1.CollectionSource) *examplev1.JsonServer {
+			return &examplev1.JsonServer{
+				Spec: examplev1.JsonServerSpec{
+					JsonConfig:  config,
+					Collections: collections,
+				},
+			}
+		}
+
+		It("should merge collections into db.json and report them", func() {
+			js := newComposed(`{"profile":{"name":"typicode"}}`,
+				examplev1.CollectionSource{Name: "users", Inline: `[{"id":1},{"id":2}]`})
+
+			r := &JsonServerReconciler{}
+			Expect(r.composeCollections(context.Background(), js)).To(Succeed())
+			Expect(js.Spec.JsonConfig).To(MatchJSON(`{"profile":{"name":"typicode"},"users":[{"id":1},{"id":2}]}`))
+			Expect(js.Status.Collections).To(Equal([]examplev1.CollectionStatus{
+				{Name: "profile", Source: "spec.jsonConfig", Items: 1},
+				{Name: "users", Source: "inline", Items: 2},
+			}))
+		})
+
+		It("should keep jsonConfig unchanged without collections", func() {
+			js := newComposed(`{ "people": [] }`)
+
+			r := &JsonServerReconciler{}
+			Expect(r.composeCollections(context.Background(), js)).To(Succeed())
+			Expect(js.Spec.JsonConfig).To(Equal(`{ "people": [] }`))
+			Expect(js.Status.Collections).To(Equal([]examplev1.CollectionStatus{
+				{Name: "people", Source: "spec.jsonConfig", Items: 0},
+			}))
+		})
+
+		It("should import CSV and NDJSON rows and report the skipped ones", func() {
+			js := newComposed(`{}`,
+				examplev1.CollectionSource{Name: "users", Format: examplev1.CollectionFormatCSV, Inline: "" +
+					"id,name,active,zip,score\n" +
+					"1,Ada,TRUE,01234,1.5\n" +
+					"2,Grace,false,,\n" +
+					"3,Linus\n"},
+				examplev1.CollectionSource{Name: "events", Format: examplev1.CollectionFormatNDJSON, Inline: "" +
+					`{"id": 1, "type": "login"}` + "\n\n" +
+					`[1, 2]` + "\n" +
+					`{"id": 2, "type": "logout"}` + "\n" +
+					`{"id": 3,` + "\n"})
+
+			r := &JsonServerReconciler{}
+			Expect(r.composeCollections(context.Background(), js)).To(Succeed())
+			Expect(js.Spec.JsonConfig).To(MatchJSON(`{
+				"users": [
+					{"id": 1, "name": "Ada", "active": true, "zip": "01234", "score": 1.5},
+					{"id": 2, "name": "Grace", "active": false}
+				],
+				"events": [{"id": 1, "type": "login"}, {"id": 2, "type": "logout"}]
+			}`))
+			Expect(js.Status.Collections).To(Equal([]examplev1.CollectionStatus{
+				{Name: "events", Source: "inline", Items: 2, SkippedRows: 2, RowErrors: []examplev1.RowError{
+					{Line: 3, Message: "is not a json object"},
+					{Line: 5, Message: "unexpected end of JSON input"},
+				}},
+				{Name: "users", Source: "inline", Items: 2, SkippedRows: 1, RowErrors: []examplev1.RowError{
+					{Line: 4, Message: "has 2 fields, the header row has 5"},
+				}},
+			}))
+		})
+
+		It("should cap the row errors in status", func() {
+			js := newComposed(`{}`, examplev1.CollectionSource{
+				Name:   "events",
+				Format: examplev1.CollectionFormatNDJSON,
+				Inline: strings.Repeat("[]\n", 25),
+			})
+
+			r := &JsonServerReconciler{}
+			Expect(r.composeCollections(context.Background(), js)).To(Succeed())
+			Expect(js.Status.Collections[0].SkippedRows).To(Equal(int32(25)))
+			Expect(js.Status.Collections[0].RowErrors).To(HaveLen(maxRowErrors))
+		})
+
+		It("should reject a CSV collection without a usable header", func() {
+			js := newComposed(`{}`, examplev1.CollectionSource{
+				Name:   "users",
+				Format: examplev1.CollectionFormatCSV,
+				Inline: "id,name,id\n1,Ada,2\n",
+			})
+
+			r := &JsonServerReconciler{}
+			err := r.composeCollections(context.Background(), js)
+			Expect(err).To(MatchError(errInvalidCollection))
+			Expect(err).To(MatchError(ContainSubstring(`collection "users" from inline is not valid csv: ` +
+				`line 1, column 9: duplicate field name "id" in the header row`)))
+		})
+
+		It("should reject a collection already defined by jsonConfig", func() {
+			js := newComposed(`{"users":[]}`,
+				examplev1.CollectionSource{Name: "users", Inline: `[]`})
+
+			r := &JsonServerReconciler{}
+			err := r.composeCollections(context.Background(), js)
+			Expect(err).To(MatchError(errInvalidCollection))
+			Expect(err).To(MatchError(ContainSubstring(`collection "users" is already defined by spec.jsonConfig`)))
+		})
+
+		It("should generate synthetic collections deterministically", func() {
+			newGenerated := func() *examplev1.JsonServer {
+				js := newComposed(`{"teams": [{ "id": "red" }, { "id": "blue" }]}`)
+				js.Spec.Generate = []examplev1.GenerateSpec{
+					{Name: "posts", Count: 200, Seed: 3, Fields: map[string]string{
+						"userId": "{{ref users}}",
+						"title":  "{{sentence}}",
+					}},
+					{Name: "users", Count: 50, Seed: 3, Fields: map[string]string{
+						"name":   "{{name}}",
+						"email":  "{{email}}",
+						"age":    "{{int 18 90}}",
+						"teamId": "{{ref teams}}",
+						"joined": "{{date 2024-01-01 2024-01-31}}",
+						"handle": "user-{{index}}",
+					}},
+				}
+				return js
+			}
+
+			r := &JsonServerReconciler{}
+			js := newGenerated()
+			Expect(r.composeCollections(context.Background(), js)).To(Succeed())
+			Expect(js.Status.Collections).To(ContainElements(
+				examplev1.CollectionStatus{Name: "posts", Source: "generated", Items: 200},
+				examplev1.CollectionStatus{Name: "users", Source: "generated", Items: 50},
+			))
+			Expect(examplev1.ValidateDB([]byte(js.Spec.JsonConfig), nil, dbPath(js))).To(BeEmpty())
+
+			var db struct {
+				Users []map[string]any `json:"users"`
+			}
+			Expect(json.Unmarshal([]byte(js.Spec.JsonConfig), &db)).To(Succeed())
+			Expect(db.Users[0]).To(HaveKeyWithValue("id", BeNumerically("==", 1)))
+			Expect(db.Users[0]).To(HaveKeyWithValue("handle", "user-1"))
+			Expect(db.Users[0]).To(HaveKeyWithValue("age", BeNumerically("~", 54, 36)))
+			Expect(db.Users[0]).To(HaveKeyWithValue("teamId", BeElementOf("red", "blue")))
+			Expect(db.Users[0]).To(HaveKeyWithValue("joined", HavePrefix("2024-01-")))
+
+			again := newGenerated()
+			Expect(r.composeCollections(context.Background(), again)).To(Succeed())
+			Expect(again.Spec.JsonConfig).To(Equal(js.Spec.JsonConfig))
+		})
+
+		It("should index the ConfigMaps collections are read from", func() {
+			ref := func(name string) *corev1.ConfigMapKeySelector {
+				return &corev1.ConfigMapKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: name},
+					Key:                  "data.json",
+				}
+			}
+			js := newComposed("",
+				examplev1.CollectionSource{Name: "users", ConfigMapKeyRef: ref("fixtures")},
+				examplev1.CollectionSource{Name: "posts", ConfigMapKeyRef: ref("fixtures")},
+				examplev1.CollectionSource{Name: "tags", ConfigMapKeyRef: ref("tags")},
+			)
+			Expect(dataSources(js)).To(Equal([]string{"ConfigMap/fixtures", "ConfigMap/tags"}))
+		})
+	})
+
+	Context("When validating the shape of db.json", func() {
+		It("should report errors under the source of db.json", func() {
+			js := &examplev1.JsonServer{
+				Spec: examplev1.JsonServerSpec{
+					JsonConfig: `{"users": [{ "id": 1 }]}`,
+					Collections: []examplev1.CollectionSource{
+						{Name: "pets", Inline: `[{ "id": 1, "userId": 2 }]`},
+					},
+				},
+			}
+
+			r := &JsonServerReconciler{}
+			Expect(r.composeCollections(context.Background(), js)).To(Succeed())
+			errs := examplev1.ValidateDB([]byte(js.Spec.JsonConfig), js.Spec.Server, dbPath(js))
+			Expect(errs.ToAggregate()).To(MatchError("db.json.pets[0].userId: Not found: 2"))
+		})
+
+		It("should match items against their schemas", func() {
+			js := &examplev1.JsonServer{
+				Spec: examplev1.JsonServerSpec{
+					JsonConfig: `{"users": [{ "id": 1, "name": "Ada" }, { "id": 2 }]}`,
+					Schemas: map[string]examplev1.SchemaSource{
+						"users": {Inline: `{"type": "object", "required": ["name"]}`},
+					},
+				},
+			}
+
+			r := &JsonServerReconciler{}
+			schemas, err := r.resolveSchemas(context.Background(), js)
+			Expect(err).NotTo(HaveOccurred())
+			errs := examplev1.ValidateSchemas([]byte(js.Spec.JsonConfig), schemas, dbPath(js))
+			Expect(errs.ToAggregate()).To(MatchError(
+				"spec.jsonConfig.users[1].name: Invalid value: does not match the schema at /users/1/name: is required"))
+
+			js.Spec.Schemas["users"] = examplev1.SchemaSource{Inline: `[`}
+			_, err = r.resolveSchemas(context.Background(), js)
+			Expect(err).To(MatchError(errInvalidSchema))
+		})
+
+		It("should index the ConfigMaps schemas are read from", func() {
+			js := &examplev1.JsonServer{
+				Spec: examplev1.JsonServerSpec{
+					JsonConfig: `{}`,
+					Schemas: map[string]examplev1.SchemaSource{
+						"users": {ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+							LocalObjectReference: corev1.LocalObjectReference{Name: "contracts"},
+							Key:                  "user.schema.json",
+						}},
+					},
+				},
+			}
+			Expect(dataSources(js)).To(Equal([]string{"ConfigMap/contracts"}))
+		})
+	})
+
+	Context("When generating data from OpenAPI", func() {
+		const document = `
+openapi: 3.0.3
+info:
+  title: Blog
+  version: "1"
+paths:
+  /api/v1/users:
+    get:
+      responses:
+        "200":
+          description: users
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  $ref: "#/components/schemas/User"
+  /api/v1/users/{id}:
+    get:
+      responses:
+        "200":
+          description: user
+          content:
+            application/json:
+              schema:
+                $ref: "#/components/schemas/User"
+  /api/v1/users/{id}/posts:
+    get:
+      responses:
+        "200":
+          description: posts of a user
+  /api/v1/posts:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: "#/components/schemas/Post"
+      responses:
+        "201":
+          description: created
+components:
+  schemas:
+    User:
+      type: object
+      required: [name, email]
+      properties:
+        id: { type: integer }
+        name: { type: string }
+        email: { type: string, format: email }
+        role: { type: string, enum: [admin, member] }
+    Post:
+      type: object
+      properties:
+        id: { type: string }
+        title: { type: string, maxLength: 8 }
+        userId: { type: integer }
+        tags: { type: array, minItems: 1, items: { type: string } }
+        createdAt: { type: string, format: date-time }
+`
+		generate := func(seed int64) (map[string]any, map[string]string, []examplev1.GeneratedResource) {
+			doc, err := parseOpenAPI(document)
+			Expect(err).NotTo(HaveOccurred())
+			db, routes, resources, err := (&generator{doc: doc}).generate(3, seed)
+			Expect(err).NotTo(HaveOccurred())
+			return db, routes, resources
+		}
+
+		It("should generate consistent items for every resource with routes", func() {
+			db, routes, resources := generate(7)
+			Expect(resources).To(Equal([]examplev1.GeneratedResource{
+				{Name: "posts", Path: "/api/v1/posts"},
+				{Name: "users", Path: "/api/v1/users"},
+			}))
+			Expect(routes).To(Equal(map[string]string{
+				"/api/v1/posts":     "/posts",
+				"/api/v1/posts/:id": "/posts/:id",
+				"/api/v1/users":     "/users",
+				"/api/v1/users/:id": "/users/:id",
+			}))
+
+			data, err := json.Marshal(db)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(examplev1.ValidateDB(data, nil, field.NewPath("db.json"))).To(BeEmpty())
+
+			doc, _ := parseOpenAPI(document)
+			schemas := map[string]*spec.Schema{
+				"users": doc.Components.Schemas["User"],
+				"posts": doc.Components.Schemas["Post"],
+			}
+			Expect(examplev1.ValidateSchemas(data, schemas, field.NewPath("db.json"))).To(BeEmpty())
+
+			users := db["users"].([]map[string]any)
+			Expect(users).To(HaveLen(3))
+			Expect(users[2]).To(HaveKeyWithValue("id", 3))
+			Expect(users[2]).To(HaveKeyWithValue("email", "user3@example.com"))
+			posts := db["posts"].([]map[string]any)
+			Expect(posts[0]).To(HaveKeyWithValue("id", "1"))
+			Expect(posts[0]["userId"]).To(BeElementOf(1, 2, 3))
+		})
+
+		It("should be deterministic for a seed", func() {
+			first, _, _ := generate(7)
+			again, _, _ := generate(7)
+			other, _, _ := generate(8)
+			Expect(again).To(Equal(first))
+			Expect(other).NotTo(Equal(first))
+
+			Expect(generatedSeed(7, "")).To(Equal(int64(7)))
+			Expect(generatedSeed(7, "2026-10-17")).To(Equal(generatedSeed(7, "2026-10-17")))
+			Expect(generatedSeed(7, "2026-10-17")).NotTo(Equal(generatedSeed(7, "2026-10-18")))
+		})
+
+		It("should give each item its own copy of an object example", func() {
+			doc, err := parseOpenAPI(`
+openapi: 3.0.3
+info: { title: Shop, version: "1" }
+paths:
+  /orders:
+    get:
+      responses:
+        "200":
+          description: orders
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  type: object
+                  example: { id: 0, status: open, lines: [{ sku: a1 }] }
+`)
+			Expect(err).NotTo(HaveOccurred())
+			db, _, _, err := (&generator{doc: doc}).generate(3, 7)
+			Expect(err).NotTo(HaveOccurred())
+
+			orders := db["orders"].([]map[string]any)
+			for i, order := range orders {
+				Expect(order).To(HaveKeyWithValue("id", i+1))
+				Expect(order).To(HaveKeyWithValue("status", "open"))
+			}
+			data, err := json.Marshal(db)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(examplev1.ValidateDB(data, nil, field.NewPath("db.json"))).To(BeEmpty())
+		})
+
+		It("should generate integers for the full int64 range", func() {
+			doc, err := parseOpenAPI(`
+openapi: 3.0.3
+info: { title: Bank, version: "1" }
+paths:
+  /accounts:
+    get:
+      responses:
+        "200":
+          description: accounts
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  type: object
+                  properties:
+                    balance: { type: integer, format: int64, minimum: 0, maximum: 9223372036854775807 }
+                    delta: { type: integer, format: int64, minimum: -9223372036854775808 }
+                    entries: { type: array, minItems: 9223372036854775807, items: { type: integer } }
+`)
+			Expect(err).NotTo(HaveOccurred())
+			db, _, _, err := (&generator{doc: doc}).generate(3, 7)
+			Expect(err).NotTo(HaveOccurred())
+
+			for _, account := range db["accounts"].([]map[string]any) {
+				Expect(account["balance"]).To(BeNumerically(">=", 0))
+				Expect(account["entries"]).To(HaveLen(maxArrayItems))
+			}
+		})
+
+		It("should cap the minLength of generated strings", func() {
+			doc, err := parseOpenAPI(`
+openapi: 3.0.3
+info: { title: Notes, version: "1" }
+paths:
+  /notes:
+    get:
+      responses:
+        "200":
+          description: notes
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  type: object
+                  properties:
+                    body: { type: string, minLength: 9223372036854775807 }
+                    tag: { type: string, maxLength: 0 }
+`)
+			Expect(err).NotTo(HaveOccurred())
+			db, _, _, err := (&generator{doc: doc}).generate(3, 7)
+			Expect(err).NotTo(HaveOccurred())
+
+			for _, note := range db["notes"].([]map[string]any) {
+				Expect(note["body"]).To(HaveLen(maxStringLength))
+				Expect(note["tag"]).To(BeEmpty())
+			}
+		})
+
+		It("should stop generating data beyond the size budget", func() {
+			doc, err := parseOpenAPI(`
+openapi: 3.0.3
+info: { title: Grid, version: "1" }
+paths:
+  /grids:
+    get:
+      responses:
+        "200":
+          description: grids
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  type: object
+                  properties:
+                    cells:
+                      type: array
+                      minItems: 100
+                      items:
+                        type: array
+                        minItems: 100
+                        items:
+                          type: array
+                          minItems: 100
+                          items: { type: integer }
+`)
+			Expect(err).NotTo(HaveOccurred())
+			_, _, _, err = (&generator{doc: doc}).generate(1000, 7)
+			Expect(err).To(MatchError(ContainSubstring("is larger than")))
+		})
+
+		DescribeTable("should reject negative sizes",
+			func(property, want string) {
+				_, err := parseOpenAPI(`
+openapi: 3.0.3
+info: { title: Notes, version: "1" }
+paths: {}
+components:
+  schemas:
+    Note:
+      type: object
+      properties:
+        ` + property)
+				Expect(err).To(MatchError(want))
+			},
+			Entry("maxLength", "body: { type: string, maxLength: -1 }",
+				"components.schemas.Note.properties.body: maxLength is -1, must not be negative"),
+			Entry("minLength", "body: { type: string, minLength: -1 }",
+				"components.schemas.Note.properties.body: minLength is -1, must not be negative"),
+			Entry("minItems", "tags: { type: array, minItems: -1, items: { allOf: [{ type: string, maxLength: -2 }] } }",
+				"components.schemas.Note.properties.tags: minItems is -1, must not be negative"),
+			Entry("nested schemas", "tags: { type: array, items: { allOf: [{ type: string, maxLength: -2 }] } }",
+				"components.schemas.Note.properties.tags.items.allOf[0]: maxLength is -2, must not be negative"),
+		)
+
+		It("should reject documents that are not OpenAPI 3", func() {
+			_, err := parseOpenAPI(`swagger: "2.0"`)
+			Expect(err).To(MatchError(ContainSubstring("not 3.x")))
+		})
+	})
+
+	Context("When reading live data", func() {
+		It("should read /db from a running json-server", func() {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				Expect(req.URL.Path).To(Equal("/db"))
+				_, _ = w.Write([]byte(`{"people":[{"id":1}]}`))
+			}))
+			defer server.Close()
+
+			r := &JsonServerReconciler{HTTPClient: server.Client()}
+			data, err := r.fetchDB(context.Background(), server.URL+"/db")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(data)).To(Equal(`{"people":[{"id":1}]}`))
+		})
+	})
+
+	Context("When promoting a snapshot", func() {
+		const resourceName = "app-snapshot"
+
+		ctx := context.Background()
+		namespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+
+		AfterEach(func() {
+			js := &examplev1.JsonServer{}
+			if err := k8sClient.Get(ctx, namespacedName, js); err == nil {
+				Expect(k8sClient.Delete(ctx, js)).To(Succeed())
+			}
+		})
+
+		It("should promote a snapshot into spec.jsonConfig", func() {
+			By("Creating a snapshot ConfigMap for the instance")
+			snapshot := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resourceName + "-snapshot-test",
+					Namespace: "default",
+					Labels:    map[string]string{examplev1.InstanceLabel: resourceName},
+				},
+				Data: map[string]string{"db.json": `{"people":[{"id":2}]}`},
+			}
+			Expect(k8sClient.Create(ctx, snapshot)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, snapshot)).To(Succeed())
+			})
+
+			By("Creating a JsonServer that asks for the snapshot to be promoted")
+			js := &examplev1.JsonServer{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resourceName,
+					Namespace: "default",
+					Annotations: map[string]string{
+						examplev1.PromoteSnapshotAnnotation: snapshot.Name,
+					},
+				},
+				Spec: examplev1.JsonServerSpec{
+					JsonConfig: `{"people":[]}`,
+				},
+			}
+			Expect(k8sClient.Create(ctx, js)).To(Succeed())
+
+			By("Waiting for jsonConfig to hold the snapshot and the annotation to be removed")
+			Eventually(func(g Gomega) {
+				js := &examplev1.JsonServer{}
+				g.Expect(k8sClient.Get(ctx, namespacedName, js)).To(Succeed())
+				g.Expect(js.Spec.JsonConfig).To(Equal(`{"people":[{"id":2}]}`))
+				g.Expect(js.Annotations).NotTo(HaveKey(examplev1.PromoteSnapshotAnnotation))
+			}).Should(Succeed())
+		})
+	})
+
+	Context("When rendering replica consistency modes", func() {
+		newScaled := func(mode examplev1.ConsistencyMode) *examplev1.JsonServer {
+			replicas := int32(3)
+			return &examplev1.JsonServer{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "app-scaled",
+					Namespace: "default",
+				},
+				Spec: examplev1.JsonServerSpec{
+					Replicas:    &replicas,
+					JsonConfig:  `{}`,
+					Consistency: &examplev1.ConsistencySpec{Mode: mode},
+				},
+			}
+		}
+
+		It("should run scaled replicas read-only in ReadOnlyWhenScaled mode", func() {
+			Expect(serverArgs(newScaled(examplev1.ConsistencyReadOnlyWhenScaled))).To(ContainElement("--read-only"))
+			Expect(serverArgs(newScaled(examplev1.ConsistencyIndependent))).NotTo(ContainElement("--read-only"))
+		})
+
+		It("should sync readers from a single writer in SingleWriter mode", func() {
+			js := newScaled(examplev1.ConsistencySingleWriter)
+			r := &JsonServerReconciler{}
+
+			readers := r.desiredDeployment(js, 3)
+			pod := readers.Spec.Template.Spec
+			Expect(pod.Containers).To(HaveLen(2))
+			Expect(pod.Containers[0].Args).To(Equal([]string{
+				"/data/db.json", "--middlewares", "/data/json-server-forward-writes.js", "--port", "3000", "--watch",
+			}))
+			Expect(pod.Containers[1].Env).To(ContainElement(corev1.EnvVar{
+				Name: "WRITER_URL", Value: "http://app-scaled-writer.default.svc:3000",
+			}))
+			Expect(configMapData(js)).To(HaveKey("json-server-forward-writes.js"))
+
+			writer := r.desiredWriterDeployment(js)
+			Expect(writer.Name).To(Equal("app-scaled-writer"))
+			Expect(*writer.Spec.Replicas).To(Equal(int32(1)))
+			Expect(writer.Spec.Selector.MatchLabels).To(Equal(map[string]string{"app": "app-scaled-writer"}))
+			Expect(writer.Spec.Template.Spec.Containers).To(HaveLen(1))
+		})
 	})
 })
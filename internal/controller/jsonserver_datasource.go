@@ -0,0 +1,225 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	examplev1 "github.com/BlueTurtle-bytes/json-server/api/v1"
+)
+
+// dataSourceIndex indexes JsonServers by the ConfigMaps and Secrets they
+// read data, OpenAPI documents, collections or schemas from, as
+// "ConfigMap/<name>" or "Secret/<name>".
+const dataSourceIndex = ".spec.dataSources"
+
+// emptyDB is served when an optional data source does not exist.
+const emptyDB = "{}"
+
+// emptyCollection is served when an optional collection does not exist.
+const emptyCollection = "[]"
+
+// errDataSourceNotFound is wrapped by errors for a missing referenced key.
+// It is not retried; the watch on the object triggers a new reconcile.
+var errDataSourceNotFound = errors.New("not found")
+
+// -------------------- Data Source --------------------
+
+// resolveDataFrom reads the data referenced by spec.dataFrom, or generates
+// it from spec.openAPI, into spec.jsonConfig of the in-memory object, so the
+// rest of the reconcile renders it like inline data. The object must not be
+// written back.
+func (r *JsonServerReconciler) resolveDataFrom(ctx context.Context, js *examplev1.JsonServer) error {
+	js.Status.Generated = nil
+
+	switch {
+	case js.Spec.OpenAPI != nil:
+		return r.generateFromOpenAPI(ctx, js)
+	case js.Spec.DataFrom == nil:
+		if js.Spec.JsonConfig == "" && composed(js) {
+			// db.json is made of collections only.
+			js.Spec.JsonConfig = emptyDB
+		}
+		return nil
+	}
+
+	data, err := r.readDataSource(ctx, js.Namespace, js.Spec.DataFrom, emptyDB)
+	if err != nil {
+		return err
+	}
+	js.Spec.JsonConfig = data
+	return nil
+}
+
+// readDataSource returns the referenced key. A missing optional object or
+// key reads as fallback.
+func (r *JsonServerReconciler) readDataSource(
+	ctx context.Context,
+	namespace string,
+	src *examplev1.DataSource,
+	fallback string,
+) (string, error) {
+
+	switch {
+	case src.ConfigMapKeyRef != nil:
+		ref := src.ConfigMapKeyRef
+		cm := &corev1.ConfigMap{}
+		err := r.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: namespace}, cm)
+		if client.IgnoreNotFound(err) != nil {
+			return "", err
+		}
+		if data, ok := cm.Data[ref.Key]; ok {
+			return data, nil
+		}
+		if data, ok := cm.BinaryData[ref.Key]; ok {
+			return string(data), nil
+		}
+		if isOptional(ref.Optional) {
+			return fallback, nil
+		}
+		return "", fmt.Errorf("Error: key %q of ConfigMap %s %w", ref.Key, ref.Name, errDataSourceNotFound)
+
+	case src.SecretKeyRef != nil:
+		ref := src.SecretKeyRef
+		secret := &corev1.Secret{}
+		err := r.secretReader().Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: namespace}, secret)
+		if client.IgnoreNotFound(err) != nil {
+			return "", err
+		}
+		if err == nil && secret.Labels[examplev1.DataSourceLabel] != "true" {
+			return "", fmt.Errorf("Error: Secret %s %w", ref.Name, errSecretNotAllowed)
+		}
+		if data, ok := secret.Data[ref.Key]; ok {
+			return string(data), nil
+		}
+		if isOptional(ref.Optional) {
+			return fallback, nil
+		}
+		return "", fmt.Errorf("Error: key %q of Secret %s %w", ref.Key, ref.Name, errDataSourceNotFound)
+	}
+
+	return "", fmt.Errorf("Error: data source sets neither configMapKeyRef nor secretKeyRef: %w", errDataSourceNotFound)
+}
+
+func isOptional(optional *bool) bool {
+	return optional != nil && *optional
+}
+
+// dataSourceName names where db.json comes from in status messages.
+func dataSourceName(js *examplev1.JsonServer) string {
+	if src := js.Spec.OpenAPI; src != nil {
+		return fmt.Sprintf("data generated from key %q of ConfigMap %s",
+			src.ConfigMapKeyRef.Key, src.ConfigMapKeyRef.Name)
+	}
+
+	switch src := js.Spec.DataFrom; {
+	case src == nil:
+		return "spec.jsonConfig"
+	case src.ConfigMapKeyRef != nil:
+		return fmt.Sprintf("key %q of ConfigMap %s", src.ConfigMapKeyRef.Key, src.ConfigMapKeyRef.Name)
+	case src.SecretKeyRef != nil:
+		return fmt.Sprintf("key %q of Secret %s", src.SecretKeyRef.Key, src.SecretKeyRef.Name)
+	}
+	return "spec.dataFrom"
+}
+
+// dataFormat is the format of the resolved data. Data generated from
+// spec.openAPI is always JSON.
+func dataFormat(js *examplev1.JsonServer) examplev1.DataFormat {
+	if js.Spec.OpenAPI != nil || js.Spec.DataFormat == "" {
+		return examplev1.DataFormatJSON
+	}
+	return js.Spec.DataFormat
+}
+
+// dbPath is the root of field paths in errors about the served db.json.
+func dbPath(js *examplev1.JsonServer) *field.Path {
+	if js.Spec.DataFrom == nil && js.Spec.OpenAPI == nil && !composed(js) {
+		return field.NewPath("spec", "jsonConfig")
+	}
+	return field.NewPath(dbFile)
+}
+
+// -------------------- Watches --------------------
+
+// dataSources lists the index values of the objects a JsonServer reads.
+func dataSources(obj client.Object) []string {
+	js, ok := obj.(*examplev1.JsonServer)
+	if !ok {
+		return nil
+	}
+
+	var sources []string
+	if src := js.Spec.DataFrom; src != nil {
+		if ref := src.ConfigMapKeyRef; ref != nil {
+			sources = append(sources, "ConfigMap/"+ref.Name)
+		}
+		if ref := src.SecretKeyRef; ref != nil {
+			sources = append(sources, "Secret/"+ref.Name)
+		}
+	}
+	if src := js.Spec.OpenAPI; src != nil {
+		sources = append(sources, "ConfigMap/"+src.ConfigMapKeyRef.Name)
+	}
+	for _, c := range js.Spec.Collections {
+		if ref := c.ConfigMapKeyRef; ref != nil && !slices.Contains(sources, "ConfigMap/"+ref.Name) {
+			sources = append(sources, "ConfigMap/"+ref.Name)
+		}
+	}
+	for _, src := range js.Spec.Schemas {
+		if ref := src.ConfigMapKeyRef; ref != nil && !slices.Contains(sources, "ConfigMap/"+ref.Name) {
+			sources = append(sources, "ConfigMap/"+ref.Name)
+		}
+	}
+	return sources
+}
+
+// requestsForDataSource maps a ConfigMap or Secret to the JsonServers that
+// read data from it.
+func (r *JsonServerReconciler) requestsForDataSource(kind string) handler.MapFunc {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		var list examplev1.JsonServerList
+		if err := r.List(ctx, &list,
+			client.InNamespace(obj.GetNamespace()),
+			client.MatchingFields{dataSourceIndex: kind + "/" + obj.GetName()},
+		); err != nil {
+			ctrl.LoggerFrom(ctx).Error(err, "failed to list JsonServers for data source",
+				"kind", kind, "name", obj.GetName())
+			return nil
+		}
+
+		requests := make([]reconcile.Request, 0, len(list.Items))
+		for _, js := range list.Items {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: client.ObjectKeyFromObject(&js),
+			})
+		}
+		return requests
+	}
+}
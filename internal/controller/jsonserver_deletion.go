@@ -0,0 +1,131 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	examplev1 "github.com/BlueTurtle-bytes/json-server/api/v1"
+)
+
+// deletionSnapshotRequest is recorded in status.lastSnapshot for the
+// snapshot taken on deletion, so a retried finalization does not take
+// another one.
+const deletionSnapshotRequest = "deletion"
+
+// -------------------- Finalizer --------------------
+
+// finalize applies spec.deletionPolicy to a deleted JsonServer and removes
+// the finalizer, after which garbage collection deletes the resources that
+// are still owned.
+func (r *JsonServerReconciler) finalize(ctx context.Context, js *examplev1.JsonServer) error {
+	switch js.Spec.DeletionPolicy {
+	case examplev1.DeletionPolicySnapshot:
+		if err := r.deletionSnapshot(ctx, js); err != nil {
+			return err
+		}
+	case examplev1.DeletionPolicyRetain:
+		key := types.NamespacedName{Name: js.Name, Namespace: js.Namespace}
+		writerKey := types.NamespacedName{Name: writerName(js), Namespace: js.Namespace}
+		for _, owned := range []struct {
+			key types.NamespacedName
+			obj client.Object
+		}{
+			{key, &corev1.PersistentVolumeClaim{}},
+			{key, &corev1.Service{}},
+			{writerKey, &corev1.Service{}},
+		} {
+			if err := r.orphan(ctx, js, owned.key, owned.obj); err != nil {
+				return err
+			}
+		}
+	}
+	return r.patchFinalizer(ctx, js, controllerutil.RemoveFinalizer)
+}
+
+// deletionSnapshot saves the live data before the pods go away. A failure
+// keeps the finalizer so the snapshot is retried; setting deletionPolicy to
+// Delete gives up on it.
+func (r *JsonServerReconciler) deletionSnapshot(ctx context.Context, js *examplev1.JsonServer) error {
+	if js.Status.LastSnapshot != nil && js.Status.LastSnapshot.Request == deletionSnapshotRequest {
+		return nil
+	}
+
+	cm, err := r.takeSnapshot(ctx, js)
+	if err != nil {
+		if js.Status.LastSnapshot == nil {
+			js.Status.LastSnapshot = &examplev1.SnapshotStatus{}
+		}
+		js.Status.LastSnapshot.Message = fmt.Sprintf("Error: snapshot on deletion failed: %v", err)
+		return errors.Join(err, r.updateStatus(ctx, js))
+	}
+
+	takenAt := metav1.NewTime(cm.CreationTimestamp.Time)
+	js.Status.LastSnapshot = &examplev1.SnapshotStatus{
+		Request:       deletionSnapshotRequest,
+		ConfigMapName: cm.Name,
+		TakenAt:       &takenAt,
+	}
+	return r.updateStatus(ctx, js)
+}
+
+// orphan drops the owner reference to the JsonServer from the named object
+// if the JsonServer controls it, so garbage collection keeps the object.
+func (r *JsonServerReconciler) orphan(
+	ctx context.Context,
+	js *examplev1.JsonServer,
+	key types.NamespacedName,
+	obj client.Object,
+) error {
+	if err := r.Get(ctx, key, obj); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if !metav1.IsControlledBy(obj, js) {
+		return nil
+	}
+
+	patch := client.MergeFrom(obj.DeepCopyObject().(client.Object))
+	obj.SetOwnerReferences(slices.DeleteFunc(obj.GetOwnerReferences(), func(ref metav1.OwnerReference) bool {
+		return ref.UID == js.UID
+	}))
+	return client.IgnoreNotFound(r.Patch(ctx, obj, patch))
+}
+
+// patchFinalizer adds or removes the finalizer with a metadata patch, so the
+// defaults and data resolved in memory are never written back to the spec.
+func (r *JsonServerReconciler) patchFinalizer(
+	ctx context.Context,
+	js *examplev1.JsonServer,
+	change func(client.Object, string) bool,
+) error {
+	original := js.DeepCopy()
+	if !change(js, examplev1.Finalizer) {
+		return nil
+	}
+	patch := client.MergeFromWithOptions(original, client.MergeFromWithOptimisticLock{})
+	return client.IgnoreNotFound(r.Patch(ctx, js, patch))
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	examplev1 "github.com/BlueTurtle-bytes/json-server/api/v1"
+)
+
+// Reasons of the Events emitted about a JsonServer, in addition to
+// examplev1.ReasonInvalidConfig.
+const (
+	eventReasonCreated            = "Created"
+	eventReasonUpdated            = "Updated"
+	eventReasonRolloutStarted     = "RolloutStarted"
+	eventReasonRolloutCompleted   = "RolloutCompleted"
+	eventReasonStatusUpdateFailed = "StatusUpdateFailed"
+)
+
+// -------------------- Events --------------------
+
+// event emits an Event about js. related is the child object the Event is
+// about and may be nil. Nothing is emitted without a Recorder.
+func (r *JsonServerReconciler) event(
+	js *examplev1.JsonServer,
+	related runtime.Object,
+	eventType, reason, action, note string,
+	args ...any,
+) {
+	if r.Recorder == nil {
+		return
+	}
+	r.Recorder.Eventf(js, related, eventType, reason, action, note, args...)
+}
+
+// childEvent emits a Normal Event that a child object was created or
+// updated. kind is passed in because typed objects read from the API
+// server carry no TypeMeta.
+func (r *JsonServerReconciler) childEvent(js *examplev1.JsonServer, obj client.Object, kind, reason string) {
+	action := "Create"
+	if reason == eventReasonUpdated {
+		action = "Update"
+	}
+	r.event(js, obj, corev1.EventTypeNormal, reason, action, "%s %s %s", reason, kind, obj.GetName())
+}
+
+// rolloutEvent emits a Normal Event that deploy started rolling out its
+// pod template.
+func (r *JsonServerReconciler) rolloutEvent(js *examplev1.JsonServer, deploy *appsv1.Deployment) {
+	r.event(js, deploy, corev1.EventTypeNormal, eventReasonRolloutStarted, "Rollout",
+		"Deployment %s started rolling out config %s",
+		deploy.Name, deploy.Spec.Template.Annotations[examplev1.ConfigHashAnnotation])
+}
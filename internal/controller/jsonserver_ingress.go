@@ -0,0 +1,281 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"reflect"
+	"strings"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	examplev1 "github.com/BlueTurtle-bytes/json-server/api/v1"
+)
+
+// httpRouteGVK is the Gateway API HTTPRoute. It is handled as unstructured
+// data so the operator runs on clusters without the Gateway API CRDs.
+var httpRouteGVK = schema.GroupVersionKind{
+	Group:   "gateway.networking.k8s.io",
+	Version: "v1",
+	Kind:    "HTTPRoute",
+}
+
+func newHTTPRoute() *unstructured.Unstructured {
+	route := &unstructured.Unstructured{}
+	route.SetGroupVersionKind(httpRouteGVK)
+	return route
+}
+
+// -------------------- Ingress --------------------
+
+// reconcileIngress creates the Ingress, or the HTTPRoute when a Gateway is
+// referenced, for spec.ingress and removes the other one. It returns the
+// public URL, which is empty when spec.ingress is unset.
+func (r *JsonServerReconciler) reconcileIngress(ctx context.Context, js *examplev1.JsonServer) (string, error) {
+	key := types.NamespacedName{Name: js.Name, Namespace: js.Namespace}
+	spec := js.Spec.Ingress
+
+	if spec == nil || spec.Gateway != nil {
+		if err := r.deleteOwned(ctx, js, key, &networkingv1.Ingress{}); err != nil {
+			return "", err
+		}
+	}
+	if spec == nil || spec.Gateway == nil {
+		// Without the Gateway API CRDs there is no HTTPRoute to remove.
+		if err := r.deleteOwned(ctx, js, key, newHTTPRoute()); err != nil && !meta.IsNoMatchError(err) {
+			return "", err
+		}
+	}
+
+	switch {
+	case spec == nil:
+		return "", nil
+	case spec.Gateway != nil:
+		if err := r.applyHTTPRoute(ctx, js, desiredHTTPRoute(js)); err != nil {
+			return "", err
+		}
+	default:
+		if err := r.applyIngress(ctx, js, desiredIngress(js)); err != nil {
+			return "", err
+		}
+	}
+
+	return publicURL(spec), nil
+}
+
+// applyIngress creates the desired Ingress or brings the existing one in
+// line with it. Annotations added by others are kept.
+func (r *JsonServerReconciler) applyIngress(
+	ctx context.Context,
+	js *examplev1.JsonServer,
+	desired *networkingv1.Ingress,
+) error {
+
+	ing := &networkingv1.Ingress{}
+	err := r.Get(ctx, client.ObjectKeyFromObject(desired), ing)
+
+	if apierrors.IsNotFound(err) {
+		if err := controllerutil.SetControllerReference(js, desired, r.Scheme); err != nil {
+			return err
+		}
+		return r.Create(ctx, desired)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	var updated bool
+	ing.Annotations, updated = mergeStringMap(ing.Annotations, desired.Annotations)
+
+	if !equality.Semantic.DeepEqual(ing.Spec, desired.Spec) {
+		ing.Spec = desired.Spec
+		updated = true
+	}
+
+	if updated {
+		return r.Update(ctx, ing)
+	}
+	return nil
+}
+
+func desiredIngress(js *examplev1.JsonServer) *networkingv1.Ingress {
+	spec := js.Spec.Ingress
+	pathType := networkingv1.PathTypePrefix
+
+	ing := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        js.Name,
+			Namespace:   js.Namespace,
+			Annotations: spec.Annotations,
+		},
+		Spec: networkingv1.IngressSpec{
+			IngressClassName: spec.ClassName,
+			Rules: []networkingv1.IngressRule{
+				{
+					Host: spec.Host,
+					IngressRuleValue: networkingv1.IngressRuleValue{
+						HTTP: &networkingv1.HTTPIngressRuleValue{
+							Paths: []networkingv1.HTTPIngressPath{
+								{
+									Path:     ingressPath(spec),
+									PathType: &pathType,
+									Backend: networkingv1.IngressBackend{
+										Service: &networkingv1.IngressServiceBackend{
+											Name: js.Name,
+											Port: networkingv1.ServiceBackendPort{
+												Number: servicePort(js),
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	if spec.TLSSecretName != "" {
+		ing.Spec.TLS = []networkingv1.IngressTLS{
+			{
+				Hosts:      []string{spec.Host},
+				SecretName: spec.TLSSecretName,
+			},
+		}
+	}
+
+	return ing
+}
+
+// -------------------- HTTPRoute --------------------
+
+// applyHTTPRoute creates the desired HTTPRoute or brings the existing one in
+// line with it. Annotations added by others are kept.
+func (r *JsonServerReconciler) applyHTTPRoute(
+	ctx context.Context,
+	js *examplev1.JsonServer,
+	desired *unstructured.Unstructured,
+) error {
+
+	route := newHTTPRoute()
+	err := r.Get(ctx, client.ObjectKeyFromObject(desired), route)
+
+	if apierrors.IsNotFound(err) {
+		if err := controllerutil.SetControllerReference(js, desired, r.Scheme); err != nil {
+			return err
+		}
+		return r.Create(ctx, desired)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	annotations, updated := mergeStringMap(route.GetAnnotations(), desired.GetAnnotations())
+	route.SetAnnotations(annotations)
+
+	if !reflect.DeepEqual(route.Object["spec"], desired.Object["spec"]) {
+		route.Object["spec"] = desired.Object["spec"]
+		updated = true
+	}
+
+	if updated {
+		return r.Update(ctx, route)
+	}
+	return nil
+}
+
+// desiredHTTPRoute routes spec.ingress.host and path from the referenced
+// Gateway to the instance Service. Fields the API server defaults are set
+// explicitly so an unchanged route compares equal.
+func desiredHTTPRoute(js *examplev1.JsonServer) *unstructured.Unstructured {
+	spec := js.Spec.Ingress
+
+	parentRef := map[string]any{
+		"group": httpRouteGVK.Group,
+		"kind":  "Gateway",
+		"name":  spec.Gateway.Name,
+	}
+	if spec.Gateway.Namespace != "" {
+		parentRef["namespace"] = spec.Gateway.Namespace
+	}
+	if spec.Gateway.SectionName != "" {
+		parentRef["sectionName"] = spec.Gateway.SectionName
+	}
+
+	route := newHTTPRoute()
+	route.SetName(js.Name)
+	route.SetNamespace(js.Namespace)
+	route.SetAnnotations(spec.Annotations)
+	route.Object["spec"] = map[string]any{
+		"parentRefs": []any{parentRef},
+		"hostnames":  []any{spec.Host},
+		"rules": []any{
+			map[string]any{
+				"matches": []any{
+					map[string]any{
+						"path": map[string]any{
+							"type":  "PathPrefix",
+							"value": ingressPath(spec),
+						},
+					},
+				},
+				"backendRefs": []any{
+					map[string]any{
+						"group":  "",
+						"kind":   "Service",
+						"name":   js.Name,
+						"port":   int64(servicePort(js)),
+						"weight": int64(1),
+					},
+				},
+			},
+		},
+	}
+
+	return route
+}
+
+// -------------------- Helpers --------------------
+
+func ingressPath(spec *examplev1.IngressSpec) string {
+	if spec.Path == "" {
+		return "/"
+	}
+	return spec.Path
+}
+
+// publicURL is the address clients outside the cluster use.
+func publicURL(spec *examplev1.IngressSpec) string {
+	scheme := "http"
+	if spec.TLSSecretName != "" || (spec.Gateway != nil && spec.Gateway.HTTPS) {
+		scheme = "https"
+	}
+	return scheme + "://" + spec.Host + strings.TrimSuffix(ingressPath(spec), "/")
+}
@@ -0,0 +1,164 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	examplev1 "github.com/BlueTurtle-bytes/json-server/api/v1"
+)
+
+// Phases of the reconcile_errors_total metric.
+const (
+	phaseConfigMap  = "configmap"
+	phaseStorage    = "storage"
+	phaseDeployment = "deployment"
+	phaseService    = "service"
+	phaseIngress    = "ingress"
+)
+
+// Labels of the per-instance series. They are prefixed, since Prometheus
+// renames namespace and name to exported_namespace and exported_name when
+// they clash with the target labels of the operator pod.
+const (
+	labelNamespace = "jsonserver_namespace"
+	labelName      = "jsonserver_name"
+)
+
+// Operator metrics, served with the controller-runtime metrics on the
+// manager's metrics endpoint. Per-instance series are labelled with the
+// namespace and name of the JsonServer and deleted with it.
+var (
+	instancesByState = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "jsonserver_instances",
+		Help: "Number of JsonServers by status.state.",
+	}, []string{"state"})
+
+	reconcileErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "jsonserver_reconcile_errors_total",
+		Help: "Number of failed attempts to reconcile a child resource of a JsonServer, by phase.",
+	}, []string{"phase"})
+
+	configSizeBytes = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "jsonserver_config_size_bytes",
+		Help: "Size in bytes of the db.json a JsonServer serves, before compression.",
+	}, []string{labelNamespace, labelName})
+
+	collectionCount = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "jsonserver_collections",
+		Help: "Number of top-level collections of the db.json a JsonServer serves.",
+	}, []string{labelNamespace, labelName})
+
+	itemCount = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "jsonserver_items",
+		Help: "Number of items in all collections of the db.json a JsonServer serves.",
+	}, []string{labelNamespace, labelName})
+
+	lastSyncTimestamp = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "jsonserver_last_sync_timestamp_seconds",
+		Help: "Unix time a JsonServer was last reconciled into the Synced state.",
+	}, []string{labelNamespace, labelName})
+)
+
+func init() {
+	metrics.Registry.MustRegister(
+		instancesByState,
+		reconcileErrors,
+		configSizeBytes,
+		collectionCount,
+		itemCount,
+		lastSyncTimestamp,
+	)
+
+	// Report every state and phase from the start, so rates and alerts
+	// do not depend on a first occurrence.
+	for _, state := range []string{examplev1.StateSynced, examplev1.StateError} {
+		instancesByState.WithLabelValues(state)
+	}
+	for _, phase := range []string{phaseConfigMap, phaseStorage, phaseDeployment, phaseService, phaseIngress} {
+		reconcileErrors.WithLabelValues(phase)
+	}
+}
+
+// states remembers the last reported state of each JsonServer, so
+// jsonserver_instances moves an instance from one state to the other.
+var states = struct {
+	sync.Mutex
+	byKey map[types.NamespacedName]string
+}{byKey: map[types.NamespacedName]string{}}
+
+// -------------------- Metrics --------------------
+
+// recordStatusMetrics reports the state and collections of js. synced is
+// true when the status was written, which counts as a sync when the state
+// is Synced.
+func recordStatusMetrics(js *examplev1.JsonServer, synced bool) {
+	key := types.NamespacedName{Namespace: js.Namespace, Name: js.Name}
+	setState(key, js.Status.State)
+
+	items := 0
+	for _, c := range js.Status.Collections {
+		items += int(c.Items)
+	}
+	collectionCount.WithLabelValues(key.Namespace, key.Name).Set(float64(len(js.Status.Collections)))
+	itemCount.WithLabelValues(key.Namespace, key.Name).Set(float64(items))
+
+	if synced && js.Status.State == examplev1.StateSynced {
+		lastSyncTimestamp.WithLabelValues(key.Namespace, key.Name).Set(float64(time.Now().Unix()))
+	}
+}
+
+// recordConfigSize reports the size of the db.json rendered into the
+// ConfigMap of js.
+func recordConfigSize(js *examplev1.JsonServer) {
+	configSizeBytes.WithLabelValues(js.Namespace, js.Name).Set(float64(len(js.Spec.JsonConfig)))
+}
+
+// forgetMetrics deletes the series of a JsonServer that no longer exists.
+func forgetMetrics(key types.NamespacedName) {
+	setState(key, "")
+
+	labels := prometheus.Labels{labelNamespace: key.Namespace, labelName: key.Name}
+	for _, vec := range []*prometheus.GaugeVec{configSizeBytes, collectionCount, itemCount, lastSyncTimestamp} {
+		vec.Delete(labels)
+	}
+}
+
+// setState moves the instance to state in jsonserver_instances. An empty
+// state removes it.
+func setState(key types.NamespacedName, state string) {
+	states.Lock()
+	defer states.Unlock()
+
+	old, ok := states.byKey[key]
+	if ok && old == state {
+		return
+	}
+	if ok {
+		instancesByState.WithLabelValues(old).Dec()
+		delete(states.byKey, key)
+	}
+	if state != "" {
+		instancesByState.WithLabelValues(state).Inc()
+		states.byKey[key] = state
+	}
+}
@@ -0,0 +1,665 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"maps"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/kube-openapi/pkg/spec3"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"sigs.k8s.io/yaml"
+
+	examplev1 "github.com/BlueTurtle-bytes/json-server/api/v1"
+	"github.com/BlueTurtle-bytes/json-server/internal/generate"
+)
+
+// errInvalidOpenAPI is wrapped by errors for documents that cannot be used.
+// It is not retried; fixing the referenced ConfigMap triggers a new reconcile.
+var errInvalidOpenAPI = errors.New("invalid OpenAPI document")
+
+const (
+	// maxSchemaDepth stops generating nested objects and arrays, which also
+	// ends recursive schemas.
+	maxSchemaDepth = 4
+	// maxRefs stops resolving a chain of $refs that never ends.
+	maxRefs = 16
+	// maxArrayItems caps the minItems of generated arrays.
+	maxArrayItems = 100
+	// maxStringLength caps the minLength of generated strings.
+	maxStringLength = 1024
+	// maxGeneratedBytes bounds the estimated size of the generated db.json,
+	// since nested arrays multiply their minItems. Most generated data
+	// compresses below the size a ConfigMap can hold.
+	maxGeneratedBytes = 8 * examplev1.MaxConfigMapBytes
+)
+
+// generatedEpoch is the base of generated dates, so they do not depend on
+// the time of the reconcile.
+var generatedEpoch = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// -------------------- OpenAPI --------------------
+
+// generateFromOpenAPI generates db.json from spec.openAPI into spec.jsonConfig
+// of the in-memory object and adds the generated routes to spec.server.routes
+// unless already set. The object must not be written back.
+func (r *JsonServerReconciler) generateFromOpenAPI(ctx context.Context, js *examplev1.JsonServer) error {
+	src := js.Spec.OpenAPI
+	data, err := r.readDataSource(ctx, js.Namespace,
+		&examplev1.DataSource{ConfigMapKeyRef: &src.ConfigMapKeyRef}, "")
+	if err != nil {
+		return err
+	}
+
+	regenerate := js.Annotations[examplev1.RegenerateAnnotation]
+	seed := generatedSeed(src.Seed, regenerate)
+	status := &examplev1.GeneratedStatus{
+		Source:     fmt.Sprintf("key %q of ConfigMap %s", src.ConfigMapKeyRef.Key, src.ConfigMapKeyRef.Name),
+		Seed:       seed,
+		Regenerate: regenerate,
+	}
+
+	// A missing optional document generates an empty database.
+	if data == "" {
+		js.Spec.JsonConfig = emptyDB
+		js.Status.Generated = status
+		return nil
+	}
+
+	doc, err := parseOpenAPI(data)
+	if err != nil {
+		return fmt.Errorf("Error: %s is not an OpenAPI 3 document: %v: %w", status.Source, err, errInvalidOpenAPI)
+	}
+
+	items := src.Items
+	if items == 0 {
+		items = examplev1.DefaultGeneratedItems
+	}
+	server := js.Spec.Server
+	if server == nil {
+		server = &examplev1.ServerSpec{}
+	}
+
+	g := &generator{doc: doc, idField: server.IDField, fkSuffix: server.ForeignKeySuffix}
+	db, routes, resources, err := g.generate(int(items), seed)
+	if err != nil {
+		return fmt.Errorf("Error: the data generated from %s %v: %w", status.Source, err, errInvalidOpenAPI)
+	}
+
+	config, err := json.Marshal(db)
+	if err != nil {
+		return err
+	}
+	js.Spec.JsonConfig = string(config)
+
+	if len(routes) > 0 && server.Routes == nil {
+		server.Routes = map[string]string{}
+	}
+	for from, to := range routes {
+		if _, ok := server.Routes[from]; !ok {
+			server.Routes[from] = to
+		}
+	}
+	js.Spec.Server = server
+
+	status.Resources = resources
+	js.Status.Generated = status
+	return nil
+}
+
+// generatedSeed mixes the regenerate annotation into the seed.
+func generatedSeed(seed int64, regenerate string) int64 {
+	if regenerate == "" {
+		return seed
+	}
+	return seed ^ int64(hashString(regenerate))
+}
+
+func hashString(s string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(s))
+	return h.Sum64()
+}
+
+// parseOpenAPI reads an OpenAPI 3 document given as JSON or YAML.
+func parseOpenAPI(data string) (*spec3.OpenAPI, error) {
+	jsonData, err := yaml.YAMLToJSON([]byte(data))
+	if err != nil {
+		return nil, err
+	}
+	doc := &spec3.OpenAPI{}
+	if err := json.Unmarshal(jsonData, doc); err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(doc.Version, "3.") {
+		return nil, fmt.Errorf("openapi version is %q, not 3.x", doc.Version)
+	}
+	if err := checkSizes(doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// checkSizes rejects negative lengths and item counts in the schemas the
+// generator reads, which no value can satisfy.
+func checkSizes(doc *spec3.OpenAPI) error {
+	if doc.Components != nil {
+		for _, name := range slices.Sorted(maps.Keys(doc.Components.Schemas)) {
+			if err := checkSchemaSizes(doc.Components.Schemas[name], "components.schemas."+name); err != nil {
+				return err
+			}
+		}
+	}
+	if doc.Paths != nil {
+		for _, p := range slices.Sorted(maps.Keys(doc.Paths.Paths)) {
+			if err := checkSchemaSizes(responseSchema(doc.Paths.Paths[p]), "paths."+p+".get"); err != nil {
+				return err
+			}
+			if err := checkSchemaSizes(requestSchema(doc.Paths.Paths[p]), "paths."+p+".post"); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func checkSchemaSizes(s *spec.Schema, path string) error {
+	if s == nil {
+		return nil
+	}
+	for _, size := range []struct {
+		keyword string
+		value   *int64
+	}{
+		{"maxLength", s.MaxLength},
+		{"minLength", s.MinLength},
+		{"minItems", s.MinItems},
+	} {
+		if size.value != nil && *size.value < 0 {
+			return fmt.Errorf("%s: %s is %d, must not be negative", path, size.keyword, *size.value)
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(s.Properties)) {
+		prop := s.Properties[name]
+		if err := checkSchemaSizes(&prop, path+".properties."+name); err != nil {
+			return err
+		}
+	}
+	if s.Items != nil {
+		if err := checkSchemaSizes(s.Items.Schema, path+".items"); err != nil {
+			return err
+		}
+	}
+	for _, list := range []struct {
+		keyword string
+		schemas []spec.Schema
+	}{
+		{"allOf", s.AllOf},
+		{"oneOf", s.OneOf},
+		{"anyOf", s.AnyOf},
+	} {
+		for i := range list.schemas {
+			if err := checkSchemaSizes(&list.schemas[i], fmt.Sprintf("%s.%s[%d]", path, list.keyword, i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// -------------------- Generator --------------------
+
+// apiResource is a collection found in the paths of a document.
+type apiResource struct {
+	name   string
+	path   string
+	schema *spec.Schema
+}
+
+// generator generates example items for the resources of a document. Every
+// resource draws from its own random source seeded with the seed and its
+// name, so adding a resource does not change the items of the others.
+type generator struct {
+	doc      *spec3.OpenAPI
+	idField  string
+	fkSuffix string
+
+	rng *rand.Rand
+	// size is the estimated size in bytes of the data generated so far.
+	size int
+}
+
+// generate returns db.json, the routes from the API paths to the
+// collections, and the generated resources sorted by name. It fails once
+// the data grows beyond maxGeneratedBytes.
+func (g *generator) generate(items int, seed int64) (map[string]any, map[string]string, []examplev1.GeneratedResource, error) {
+	if g.idField == "" {
+		g.idField = examplev1.DefaultIDField
+	}
+	if g.fkSuffix == "" {
+		g.fkSuffix = examplev1.DefaultForeignKeySuffix
+	}
+
+	resources := g.resources()
+	db := make(map[string]any, len(resources))
+	routes := map[string]string{}
+	status := make([]examplev1.GeneratedResource, 0, len(resources))
+
+	collections := make(map[string][]map[string]any, len(resources))
+	for _, res := range resources {
+		g.rng = rand.New(rand.NewPCG(uint64(seed), hashString(res.name)))
+
+		list := make([]map[string]any, items)
+		for i := range list {
+			item, _ := g.value(res.schema, res.name, i, 0).(map[string]any)
+			if item == nil {
+				item = map[string]any{}
+			}
+			item[g.idField] = g.id(res.schema, i)
+			list[i] = item
+			if g.size > maxGeneratedBytes {
+				return nil, nil, nil, fmt.Errorf("is larger than %d bytes, lower spec.openAPI.items or the sizes in the schemas",
+					maxGeneratedBytes)
+			}
+		}
+		collections[res.name] = list
+
+		if res.path != "/"+res.name {
+			routes[res.path] = "/" + res.name
+			routes[res.path+"/:id"] = "/" + res.name + "/:id"
+		}
+		status = append(status, examplev1.GeneratedResource{Name: res.name, Path: res.path})
+	}
+
+	// Point foreign keys at generated items, so the data is consistent.
+	for _, res := range resources {
+		g.rng = rand.New(rand.NewPCG(uint64(seed), hashString(res.name+"/"+g.fkSuffix)))
+		for _, item := range collections[res.name] {
+			for _, key := range slices.Sorted(maps.Keys(item)) {
+				ref, ok := strings.CutSuffix(key, g.fkSuffix)
+				if !ok || ref == "" || key == g.idField {
+					continue
+				}
+				target, ok := examplev1.ReferencedCollection(ref, func(name string) bool {
+					return len(collections[name]) > 0
+				})
+				if ok {
+					targets := collections[target]
+					item[key] = targets[g.rng.IntN(len(targets))][g.idField]
+				}
+			}
+		}
+	}
+
+	for name, list := range collections {
+		db[name] = list
+	}
+	return db, routes, status, nil
+}
+
+// resources finds the collections of the document: paths whose last
+// segment is static, e.g. /api/v1/users, together with the item path
+// /api/v1/users/{id}. Nested collections such as /users/{id}/posts are
+// skipped. The item schema comes from the GET responses, or else the POST
+// request body.
+func (g *generator) resources() []apiResource {
+	if g.doc.Paths == nil {
+		return nil
+	}
+	paths := g.doc.Paths.Paths
+
+	byName := map[string]apiResource{}
+	for _, p := range slices.Sorted(maps.Keys(paths)) {
+		segments := strings.Split(strings.Trim(p, "/"), "/")
+		if segments[0] == "" || slices.ContainsFunc(segments, isPathParam) {
+			continue
+		}
+
+		name := segments[len(segments)-1]
+		if _, ok := byName[name]; ok {
+			continue
+		}
+		res := apiResource{name: name, path: "/" + strings.Join(segments, "/")}
+
+		if s := g.resolve(responseSchema(paths[p])); s != nil {
+			res.schema = g.collectionItems(s)
+		}
+		if res.schema == nil {
+			if item := g.itemPath(paths, p); item != nil {
+				res.schema = g.resolve(responseSchema(item))
+			}
+		}
+		if res.schema == nil {
+			res.schema = g.resolve(requestSchema(paths[p]))
+		}
+		byName[name] = res
+	}
+
+	resources := make([]apiResource, 0, len(byName))
+	for _, name := range slices.Sorted(maps.Keys(byName)) {
+		resources = append(resources, byName[name])
+	}
+	return resources
+}
+
+// itemPath returns the path of a single item of the collection at p.
+func (g *generator) itemPath(paths map[string]*spec3.Path, p string) *spec3.Path {
+	prefix := strings.TrimSuffix(p, "/") + "/"
+	for _, candidate := range slices.Sorted(maps.Keys(paths)) {
+		rest, ok := strings.CutPrefix(candidate, prefix)
+		if ok && isPathParam(strings.Trim(rest, "/")) {
+			return paths[candidate]
+		}
+	}
+	return nil
+}
+
+// collectionItems returns the item schema of a list response: the items
+// of an array, or of the only array property of an envelope object.
+func (g *generator) collectionItems(s *spec.Schema) *spec.Schema {
+	if s.Items != nil && s.Items.Schema != nil {
+		return g.resolve(s.Items.Schema)
+	}
+
+	var items *spec.Schema
+	for _, name := range slices.Sorted(maps.Keys(s.Properties)) {
+		prop := s.Properties[name]
+		if p := g.resolve(&prop); p != nil && p.Items != nil && p.Items.Schema != nil {
+			if items != nil {
+				return nil
+			}
+			items = g.resolve(p.Items.Schema)
+		}
+	}
+	return items
+}
+
+// resolve follows $refs to schemas of the document components.
+func (g *generator) resolve(s *spec.Schema) *spec.Schema {
+	for range maxRefs {
+		if s == nil || s.Ref.String() == "" {
+			return s
+		}
+		name, ok := strings.CutPrefix(s.Ref.String(), "#/components/schemas/")
+		if !ok || g.doc.Components == nil {
+			return nil
+		}
+		s = g.doc.Components.Schemas[name]
+	}
+	return nil
+}
+
+// id returns the id of the index-th item: a number, or a string when the
+// schema declares the id a string.
+func (g *generator) id(s *spec.Schema, index int) any {
+	if s != nil {
+		prop, ok := s.Properties[g.idField]
+		if p := g.resolve(&prop); ok && p != nil && schemaType(p) == "string" {
+			return strconv.Itoa(index + 1)
+		}
+	}
+	return index + 1
+}
+
+// value generates an example value for s. name is the property the value
+// is for and index the item it belongs to; both make strings readable.
+func (g *generator) value(s *spec.Schema, name string, index, depth int) any {
+	s = g.resolve(s)
+	switch {
+	case s == nil || g.size > maxGeneratedBytes:
+		return nil
+	case s.Example != nil:
+		// Items must not share the example, since the id is set on them.
+		return g.example(s.Example)
+	case len(s.Enum) > 0:
+		return g.example(s.Enum[g.rng.IntN(len(s.Enum))])
+	case len(s.AllOf) > 0:
+		merged := &spec.Schema{}
+		merged.Properties = map[string]spec.Schema{}
+		for i := range s.AllOf {
+			if part := g.resolve(&s.AllOf[i]); part != nil {
+				maps.Copy(merged.Properties, part.Properties)
+			}
+		}
+		maps.Copy(merged.Properties, s.Properties)
+		return g.object(merged, index, depth)
+	case len(s.OneOf) > 0:
+		return g.value(&s.OneOf[0], name, index, depth)
+	case len(s.AnyOf) > 0:
+		return g.value(&s.AnyOf[0], name, index, depth)
+	}
+
+	switch schemaType(s) {
+	case "object":
+		return g.object(s, index, depth)
+	case "array":
+		return g.array(s, name, index, depth)
+	case "string":
+		value := g.text(s, name, index)
+		g.size += len(value) + len(`"",`)
+		return value
+	}
+
+	// Other values are counted at about the size of a number.
+	g.size += 8
+	switch schemaType(s) {
+	case "integer":
+		low, high := bounds(s, 1, 1000)
+		first := toInt64(math.Ceil(low))
+		return generate.IntBetween(g.rng, first, max(first, toInt64(math.Floor(high))))
+	case "number":
+		low, high := bounds(s, 0, 1000)
+		return math.Round((low+g.rng.Float64()*(high-low))*100) / 100
+	case "boolean":
+		return g.rng.IntN(2) == 1
+	}
+	return nil
+}
+
+func (g *generator) object(s *spec.Schema, index, depth int) any {
+	obj := map[string]any{}
+	if depth >= maxSchemaDepth {
+		return obj
+	}
+	for _, prop := range slices.Sorted(maps.Keys(s.Properties)) {
+		schema := s.Properties[prop]
+		g.size += len(prop) + len(`"":`)
+		obj[prop] = g.value(&schema, prop, index, depth+1)
+	}
+	return obj
+}
+
+func (g *generator) array(s *spec.Schema, name string, index, depth int) any {
+	list := []any{}
+	if depth >= maxSchemaDepth || s.Items == nil || s.Items.Schema == nil {
+		return list
+	}
+
+	low, high := int64(1), int64(3)
+	if s.MinItems != nil {
+		low = min(max(*s.MinItems, 0), maxArrayItems)
+		high = max(high, low)
+	}
+	if s.MaxItems != nil {
+		high = min(high, max(*s.MaxItems, 0))
+		low = min(low, high)
+	}
+	for range generate.IntBetween(g.rng, low, high) {
+		if g.size > maxGeneratedBytes {
+			break
+		}
+		list = append(list, g.value(s.Items.Schema, name, index, depth+1))
+	}
+	return list
+}
+
+func (g *generator) text(s *spec.Schema, name string, index int) string {
+	var value string
+	switch s.Format {
+	case "date-time":
+		value = generatedEpoch.Add(time.Duration(g.rng.IntN(365*24)) * time.Hour).Format(time.RFC3339)
+	case "date":
+		value = generatedEpoch.AddDate(0, 0, g.rng.IntN(365)).Format(time.DateOnly)
+	case "email":
+		value = fmt.Sprintf("user%d@example.com", index+1)
+	case "uri", "url":
+		value = fmt.Sprintf("https://example.com/%s/%d", name, index+1)
+	case "uuid":
+		value = fmt.Sprintf("%08x-%04x-4%03x-8%03x-%012x", g.rng.Uint32(), g.rng.IntN(1<<16),
+			g.rng.IntN(1<<12), g.rng.IntN(1<<12), g.rng.Int64N(1<<48))
+	default:
+		value = fmt.Sprintf("%s %d", name, index+1)
+	}
+
+	if s.MaxLength != nil {
+		value = value[:min(max(*s.MaxLength, 0), int64(len(value)))]
+	}
+	if s.MinLength != nil {
+		if n := min(*s.MinLength, maxStringLength) - int64(len(value)); n > 0 {
+			value += strings.Repeat("x", int(n))
+		}
+	}
+	return value
+}
+
+// example copies an example and counts its size.
+func (g *generator) example(v any) any {
+	if data, err := json.Marshal(v); err == nil {
+		g.size += len(data)
+	}
+	return copyExample(v)
+}
+
+// -------------------- Helpers --------------------
+
+// schemaType returns the type of s, inferring object and array from the
+// other keywords when no type is given.
+func schemaType(s *spec.Schema) string {
+	for _, t := range s.Type {
+		if t != "null" {
+			return t
+		}
+	}
+	switch {
+	case len(s.Properties) > 0:
+		return "object"
+	case s.Items != nil:
+		return "array"
+	}
+	return ""
+}
+
+// bounds returns the range of a number, falling back to low and high.
+func bounds(s *spec.Schema, low, high float64) (float64, float64) {
+	if s.Minimum != nil {
+		low = *s.Minimum
+		if s.ExclusiveMinimum {
+			low++
+		}
+	}
+	if s.Maximum != nil {
+		high = *s.Maximum
+		if s.ExclusiveMaximum {
+			high--
+		}
+	}
+	if high < low {
+		high = low
+	}
+	return low, high
+}
+
+// toInt64 converts f to the nearest int64, so bounds such as the maximum of
+// an int64 property do not overflow.
+func toInt64(f float64) int64 {
+	switch {
+	case math.IsNaN(f):
+		return 0
+	case f >= math.MaxInt64:
+		return math.MaxInt64
+	case f <= math.MinInt64:
+		return math.MinInt64
+	}
+	return int64(f)
+}
+
+// copyExample deep-copies an example decoded from JSON.
+func copyExample(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for key, value := range v {
+			out[key] = copyExample(value)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, value := range v {
+			out[i] = copyExample(value)
+		}
+		return out
+	}
+	return v
+}
+
+func isPathParam(segment string) bool {
+	return strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")
+}
+
+// responseSchema returns the schema of the JSON response to GET.
+func responseSchema(p *spec3.Path) *spec.Schema {
+	if p == nil || p.Get == nil || p.Get.Responses == nil {
+		return nil
+	}
+	resp := p.Get.Responses.StatusCodeResponses[200]
+	if resp == nil {
+		resp = p.Get.Responses.Default
+	}
+	if resp == nil {
+		return nil
+	}
+	return jsonSchema(resp.Content)
+}
+
+// requestSchema returns the schema of the JSON request body of POST.
+func requestSchema(p *spec3.Path) *spec.Schema {
+	if p == nil || p.Post == nil || p.Post.RequestBody == nil {
+		return nil
+	}
+	return jsonSchema(p.Post.RequestBody.Content)
+}
+
+// jsonSchema returns the schema of the first JSON media type.
+func jsonSchema(content map[string]*spec3.MediaType) *spec.Schema {
+	for _, mediaType := range slices.Sorted(maps.Keys(content)) {
+		if strings.Contains(mediaType, "json") && content[mediaType] != nil {
+			return content[mediaType].Schema
+		}
+	}
+	return nil
+}
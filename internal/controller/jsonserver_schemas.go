@@ -0,0 +1,66 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+
+	examplev1 "github.com/BlueTurtle-bytes/json-server/api/v1"
+)
+
+// emptySchema is used when an optional schema does not exist. Every item
+// matches it.
+const emptySchema = "{}"
+
+// errInvalidSchema is wrapped by errors for schemas that cannot be parsed.
+// It is not retried; fixing the spec or the referenced ConfigMap triggers a
+// new reconcile.
+var errInvalidSchema = errors.New("invalid schema")
+
+// -------------------- Schemas --------------------
+
+// resolveSchemas reads and parses spec.schemas.
+func (r *JsonServerReconciler) resolveSchemas(
+	ctx context.Context,
+	js *examplev1.JsonServer,
+) (map[string]*spec.Schema, error) {
+
+	schemas := make(map[string]*spec.Schema, len(js.Spec.Schemas))
+	for name, src := range js.Spec.Schemas {
+		data := src.Inline
+		if src.ConfigMapKeyRef != nil {
+			var err error
+			data, err = r.readDataSource(ctx, js.Namespace,
+				&examplev1.DataSource{ConfigMapKeyRef: src.ConfigMapKeyRef}, emptySchema)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		schema, err := examplev1.ParseSchema(data)
+		if err != nil {
+			return nil, fmt.Errorf("Error: schema of collection %q is not a valid JSON Schema: %w",
+				name, errInvalidSchema)
+		}
+		schemas[name] = schema
+	}
+	return schemas, nil
+}
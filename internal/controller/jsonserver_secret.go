@@ -0,0 +1,143 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"maps"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	examplev1 "github.com/BlueTurtle-bytes/json-server/api/v1"
+)
+
+// errSecretNotAllowed is wrapped by errors for a referenced Secret without
+// the data source label. It is not retried; labelling the Secret triggers a
+// new reconcile.
+var errSecretNotAllowed = errors.New("is not labelled " + examplev1.DataSourceLabel + "=true")
+
+// -------------------- Secret --------------------
+
+// secretBacked reports whether db.json comes from a Secret. Its files are
+// then rendered into a Secret instead of the ConfigMap, so Secret data never
+// ends up in a ConfigMap.
+func secretBacked(js *examplev1.JsonServer) bool {
+	return js.Spec.DataFrom != nil && js.Spec.DataFrom.SecretKeyRef != nil
+}
+
+// secretReader reads Secrets. Secrets are only watched as metadata, so
+// their data is read from the API server rather than from a cache of every
+// Secret in the cluster.
+func (r *JsonServerReconciler) secretReader() client.Reader {
+	if r.APIReader == nil {
+		return r.Client
+	}
+	return r.APIReader
+}
+
+// reconcileSecret renders the files of a Secret-backed instance into the
+// Secret it owns, named like the JsonServer.
+func (r *JsonServerReconciler) reconcileSecret(
+	ctx context.Context,
+	js *examplev1.JsonServer,
+	data map[string]string,
+	binaryData map[string][]byte,
+) error {
+
+	desired := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      js.Name,
+			Namespace: js.Namespace,
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: maps.Clone(binaryData),
+	}
+	if desired.Data == nil {
+		desired.Data = map[string][]byte{}
+	}
+	for key, value := range data {
+		desired.Data[key] = []byte(value)
+	}
+
+	secret := &corev1.Secret{}
+	err := r.secretReader().Get(ctx, types.NamespacedName{Name: js.Name, Namespace: js.Namespace}, secret)
+	if apierrors.IsNotFound(err) {
+		if err := controllerutil.SetControllerReference(js, desired, r.Scheme); err != nil {
+			return err
+		}
+		if err := r.Create(ctx, desired); err != nil {
+			return err
+		}
+		r.childEvent(js, desired, "Secret", eventReasonCreated)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if !equality.Semantic.DeepEqual(secret.Data, desired.Data) {
+		secret.Data = desired.Data
+		if err := r.Update(ctx, secret); err != nil {
+			return err
+		}
+		r.childEvent(js, secret, "Secret", eventReasonUpdated)
+	}
+	return nil
+}
+
+// deleteRendered deletes the ConfigMap or Secret named like the JsonServer
+// if the JsonServer controls it, once the files are rendered into the other
+// kind. Secrets are looked up by metadata only.
+func (r *JsonServerReconciler) deleteRendered(ctx context.Context, js *examplev1.JsonServer, obj client.Object) error {
+	if err := r.Get(ctx, types.NamespacedName{Name: js.Name, Namespace: js.Namespace}, obj); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if !metav1.IsControlledBy(obj, js) {
+		return nil
+	}
+	return client.IgnoreNotFound(r.Delete(ctx, obj))
+}
+
+// secretMetadata is an empty Secret for reads through the metadata cache.
+func secretMetadata() *metav1.PartialObjectMetadata {
+	obj := &metav1.PartialObjectMetadata{}
+	obj.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("Secret"))
+	return obj
+}
+
+// configVolumeSource mounts the rendered files from the ConfigMap, or from
+// the Secret of a Secret-backed instance.
+func configVolumeSource(js *examplev1.JsonServer) corev1.VolumeSource {
+	if secretBacked(js) {
+		return corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{SecretName: js.Name},
+		}
+	}
+	return corev1.VolumeSource{
+		ConfigMap: &corev1.ConfigMapVolumeSource{
+			LocalObjectReference: corev1.LocalObjectReference{Name: js.Name},
+		},
+	}
+}
@@ -0,0 +1,218 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	examplev1 "github.com/BlueTurtle-bytes/json-server/api/v1"
+)
+
+// maxSnapshotBytes keeps a snapshot within the ConfigMap size limit.
+const maxSnapshotBytes = 1000 * 1024
+
+// snapshotTimeout bounds a single read of /db from the running instance.
+const snapshotTimeout = 10 * time.Second
+
+// errInvalidPromotion is wrapped by errors for snapshots that cannot be
+// promoted. It is not retried; changing the object triggers a new reconcile.
+var errInvalidPromotion = errors.New("invalid snapshot promotion")
+
+// -------------------- Snapshot --------------------
+
+// reconcileSnapshot takes a snapshot when the snapshot annotation holds a
+// value that has not been handled yet.
+func (r *JsonServerReconciler) reconcileSnapshot(ctx context.Context, js *examplev1.JsonServer) error {
+	request, ok := js.Annotations[examplev1.SnapshotAnnotation]
+	if !ok || request == "" {
+		return nil
+	}
+	if js.Status.LastSnapshot != nil && js.Status.LastSnapshot.Request == request {
+		return nil
+	}
+
+	cm, err := r.takeSnapshot(ctx, js)
+	if err != nil {
+		if js.Status.LastSnapshot == nil {
+			js.Status.LastSnapshot = &examplev1.SnapshotStatus{}
+		}
+		js.Status.LastSnapshot.Message = fmt.Sprintf("Error: snapshot %q failed: %v", request, err)
+		return err
+	}
+
+	takenAt := metav1.NewTime(cm.CreationTimestamp.Time)
+	js.Status.LastSnapshot = &examplev1.SnapshotStatus{
+		Request:       request,
+		ConfigMapName: cm.Name,
+		TakenAt:       &takenAt,
+	}
+	return nil
+}
+
+// takeSnapshot reads /db from the instance Service and stores it in a new,
+// unowned ConfigMap so it outlives the JsonServer.
+func (r *JsonServerReconciler) takeSnapshot(ctx context.Context, js *examplev1.JsonServer) (*corev1.ConfigMap, error) {
+	if secretBacked(js) {
+		return nil, fmt.Errorf("the data comes from Secret %s and is not copied into a ConfigMap",
+			js.Spec.DataFrom.SecretKeyRef.Name)
+	}
+
+	data, err := r.fetchDB(ctx, serviceURL(js)+"/db")
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-snapshot-%s", js.Name, now.Format("20060102-150405")),
+			Namespace: js.Namespace,
+			Labels: map[string]string{
+				examplev1.InstanceLabel: js.Name,
+			},
+			Annotations: map[string]string{
+				examplev1.SnapshotAnnotation: now.Format(time.RFC3339),
+			},
+		},
+		Data: map[string]string{
+			dbFile: string(data),
+		},
+	}
+
+	if err := r.Create(ctx, cm); err != nil {
+		return nil, err
+	}
+	return cm, nil
+}
+
+// fetchDB reads the full database from a running json-server.
+func (r *JsonServerReconciler) fetchDB(ctx context.Context, url string) ([]byte, error) {
+	httpClient := r.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: snapshotTimeout}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s returned %s", url, resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxSnapshotBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxSnapshotBytes {
+		return nil, fmt.Errorf("data exceeds %d bytes", maxSnapshotBytes)
+	}
+	if !json.Valid(data) {
+		return nil, fmt.Errorf("GET %s did not return JSON", url)
+	}
+	return data, nil
+}
+
+// serviceURL is the in-cluster address of the instance Service, or of the
+// writer in SingleWriter mode since it holds the authoritative data.
+func serviceURL(js *examplev1.JsonServer) string {
+	if consistencyMode(js) == examplev1.ConsistencySingleWriter {
+		return writerURL(js)
+	}
+	return fmt.Sprintf("http://%s.%s.svc:%d", js.Name, js.Namespace, servicePort(js))
+}
+
+// -------------------- Promotion --------------------
+
+// promoteSnapshot copies the snapshot named by the promote annotation into
+// spec.jsonConfig and drops the annotation. It reports whether the object
+// was updated; the update triggers a new reconcile. A failure is also set in
+// status.lastSnapshot, which the caller writes; errors wrapping
+// errInvalidPromotion need a change of the object.
+func (r *JsonServerReconciler) promoteSnapshot(ctx context.Context, js *examplev1.JsonServer) (bool, error) {
+	name, ok := js.Annotations[examplev1.PromoteSnapshotAnnotation]
+	if !ok {
+		return false, nil
+	}
+
+	if err := r.promote(ctx, js, name); err != nil {
+		if js.Status.LastSnapshot == nil {
+			js.Status.LastSnapshot = &examplev1.SnapshotStatus{}
+		}
+		js.Status.LastSnapshot.Message = fmt.Sprintf("Error: promoting snapshot %s failed: %v", name, err)
+		return false, err
+	}
+	return true, nil
+}
+
+// promote copies the named snapshot into spec.jsonConfig.
+func (r *JsonServerReconciler) promote(ctx context.Context, js *examplev1.JsonServer, name string) error {
+	if js.Spec.DataFrom != nil {
+		return fmt.Errorf("spec.dataFrom is set, copy the data into the referenced object instead: %w",
+			errInvalidPromotion)
+	}
+	if js.Spec.OpenAPI != nil {
+		return fmt.Errorf("spec.openAPI is set, remove it to serve the snapshot: %w", errInvalidPromotion)
+	}
+	// The snapshot holds the collections and generated data as well, which
+	// would clash with the ones added to jsonConfig on every reconcile.
+	if composed(js) {
+		return fmt.Errorf("spec.collections or spec.generate is set, remove them to serve the snapshot: %w",
+			errInvalidPromotion)
+	}
+
+	cm := &corev1.ConfigMap{}
+	if err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: js.Namespace}, cm); err != nil {
+		if apierrors.IsNotFound(err) {
+			return fmt.Errorf("ConfigMap %s not found: %w", name, errInvalidPromotion)
+		}
+		return err
+	}
+
+	// Only snapshots of this instance can be promoted, so the annotation
+	// cannot be used to copy arbitrary ConfigMaps into the spec.
+	if cm.Labels[examplev1.InstanceLabel] != js.Name {
+		return fmt.Errorf("ConfigMap %s is not a snapshot of %s: %w", name, js.Name, errInvalidPromotion)
+	}
+	data, ok := cm.Data[dbFile]
+	if !ok {
+		return fmt.Errorf("ConfigMap %s has no %s key: %w", name, dbFile, errInvalidPromotion)
+	}
+
+	// Snapshots are the JSON json-server wrote.
+	js.Spec.JsonConfig = data
+	js.Spec.DataFormat = examplev1.DataFormatJSON
+	delete(js.Annotations, examplev1.PromoteSnapshotAnnotation)
+	return r.Update(ctx, js)
+}
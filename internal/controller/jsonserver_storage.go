@@ -0,0 +1,241 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	examplev1 "github.com/BlueTurtle-bytes/json-server/api/v1"
+)
+
+// Volumes and paths used by the persistent storage mode.
+const (
+	configVolume = "json-config"
+	dataVolume   = "data"
+	seedDir      = "/seed"
+	seedHashFile = ".seed-hash"
+)
+
+// -------------------- PersistentVolumeClaim --------------------
+
+// reconcilePVC creates the claim backing spec.storage, grows it when the
+// requested size increases, and deletes it once spec.storage is removed.
+// It returns nil when no claim is wanted.
+func (r *JsonServerReconciler) reconcilePVC(
+	ctx context.Context,
+	js *examplev1.JsonServer,
+) (*corev1.PersistentVolumeClaim, error) {
+
+	pvc := &corev1.PersistentVolumeClaim{}
+	err := r.Get(ctx, types.NamespacedName{
+		Name:      js.Name,
+		Namespace: js.Namespace,
+	}, pvc)
+
+	if js.Spec.Storage == nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if metav1.IsControlledBy(pvc, js) {
+			return nil, client.IgnoreNotFound(r.Delete(ctx, pvc))
+		}
+		return nil, nil
+	}
+
+	desired := desiredPVC(js)
+
+	if apierrors.IsNotFound(err) {
+		if err := controllerutil.SetControllerReference(js, desired, r.Scheme); err != nil {
+			return nil, err
+		}
+		if err := r.Create(ctx, desired); err != nil {
+			return nil, err
+		}
+		return desired, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Everything but the requested size is immutable on an existing claim,
+	// and the size may only grow.
+	size := desired.Spec.Resources.Requests[corev1.ResourceStorage]
+	current := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+	if size.Cmp(current) > 0 {
+		if pvc.Spec.Resources.Requests == nil {
+			pvc.Spec.Resources.Requests = corev1.ResourceList{}
+		}
+		pvc.Spec.Resources.Requests[corev1.ResourceStorage] = size
+		if err := r.Update(ctx, pvc); err != nil {
+			return nil, err
+		}
+	}
+
+	return pvc, nil
+}
+
+func desiredPVC(js *examplev1.JsonServer) *corev1.PersistentVolumeClaim {
+	size := js.Spec.Storage.Size
+	if size.IsZero() {
+		size = resource.MustParse(examplev1.DefaultStorageSize)
+	}
+
+	return &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      js.Name,
+			Namespace: js.Namespace,
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			StorageClassName: js.Spec.Storage.StorageClassName,
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceStorage: size,
+				},
+			},
+		},
+	}
+}
+
+func pvcPhase(pvc *corev1.PersistentVolumeClaim) corev1.PersistentVolumeClaimPhase {
+	if pvc.Status.Phase == "" {
+		return corev1.ClaimPending
+	}
+	return pvc.Status.Phase
+}
+
+// -------------------- Seeding --------------------
+
+// withSeededVolume mounts a writable volume at /data in place of the
+// ConfigMap and seeds it from the ConfigMap in an init container.
+func withSeededVolume(
+	deploy *appsv1.Deployment,
+	js *examplev1.JsonServer,
+	source corev1.VolumeSource,
+	policy examplev1.ReseedPolicy,
+) {
+	pod := &deploy.Spec.Template.Spec
+	server := &pod.Containers[0]
+
+	pod.Volumes = append(pod.Volumes, corev1.Volume{
+		Name:         dataVolume,
+		VolumeSource: source,
+	})
+	pod.InitContainers = append(pod.InitContainers,
+		seedInitContainer(js, policy, server.Image, server.ImagePullPolicy))
+	server.VolumeMounts = []corev1.VolumeMount{
+		{Name: dataVolume, MountPath: dataDir},
+	}
+
+	// A ReadWriteOnce claim cannot be attached to old and new pods at once.
+	if source.PersistentVolumeClaim != nil {
+		deploy.Spec.Strategy = appsv1.DeploymentStrategy{
+			Type: appsv1.RecreateDeploymentStrategyType,
+		}
+	}
+}
+
+// claimVolume is the volume source for the spec.storage claim.
+func claimVolume(js *examplev1.JsonServer) corev1.VolumeSource {
+	return corev1.VolumeSource{
+		PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+			ClaimName: js.Name,
+		},
+	}
+}
+
+func reseedPolicy(js *examplev1.JsonServer) examplev1.ReseedPolicy {
+	if js.Spec.Storage == nil || js.Spec.Storage.ReseedPolicy == "" {
+		return examplev1.ReseedOnConfigChange
+	}
+	return js.Spec.Storage.ReseedPolicy
+}
+
+// seedScript copies routes and middlewares from the ConfigMap on every
+// start and copies db.json, unpacking it when it is compressed, according to
+// RESEED_POLICY. The hash of the seed that was last copied is kept next to
+// the data for OnConfigChange.
+const seedScript = `set -e
+for f in ` + seedDir + `/*; do
+  name=$(basename "$f")
+  case "$name" in
+    ` + dbFile + `|` + dbGzipFile + `) ;;
+    *) cp "$f" "` + dataDir + `/$name" ;;
+  esac
+done
+reseed=
+case "$RESEED_POLICY" in
+  Always) reseed=1 ;;
+  OnConfigChange) [ "$(cat ` + dataDir + `/` + seedHashFile + ` 2>/dev/null)" = "$SEED_HASH" ] || reseed=1 ;;
+esac
+if [ -n "$reseed" ] || [ ! -f ` + dataDir + `/` + dbFile + ` ]; then
+  if [ -f ` + seedDir + `/` + dbGzipFile + ` ]; then
+    gunzip -c ` + seedDir + `/` + dbGzipFile + ` > ` + dataDir + `/` + dbFile + `
+  else
+    cp ` + seedDir + `/` + dbFile + ` ` + dataDir + `/` + dbFile + `
+  fi
+  echo "$SEED_HASH" > ` + dataDir + `/` + seedHashFile + `
+fi
+`
+
+// seedInitContainer copies the rendered ConfigMap onto the volume before
+// json-server starts. It reuses the json-server image, which ships a shell,
+// so air-gapped clusters need no extra image.
+func seedInitContainer(
+	js *examplev1.JsonServer,
+	policy examplev1.ReseedPolicy,
+	image string,
+	pullPolicy corev1.PullPolicy,
+) corev1.Container {
+	return corev1.Container{
+		Name:            "seed",
+		Image:           image,
+		ImagePullPolicy: pullPolicy,
+		Command:         []string{"sh", "-c", seedScript},
+		Env: []corev1.EnvVar{
+			{Name: "RESEED_POLICY", Value: string(policy)},
+			{Name: "SEED_HASH", Value: seedHash(js)},
+		},
+		VolumeMounts: []corev1.VolumeMount{
+			{Name: configVolume, MountPath: seedDir, ReadOnly: true},
+			{Name: dataVolume, MountPath: dataDir},
+		},
+	}
+}
+
+// seedHash identifies the seed data so OnConfigChange can tell whether the
+// stored db.json came from the current jsonConfig.
+func seedHash(js *examplev1.JsonServer) string {
+	sum := sha256.Sum256([]byte(js.Spec.JsonConfig))
+	return hex.EncodeToString(sum[:8])
+}
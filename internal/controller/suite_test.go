@@ -33,9 +33,12 @@ import (
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/conversion"
 
 
 	examplev1 "github.com/BlueTurtle-bytes/json-server/api/v1"
+	examplev2 "github.com/BlueTurtle-bytes/json-server/api/v2"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -64,6 +67,8 @@ var _ = BeforeSuite(func() {
 	var err error
 	err = examplev1.AddToScheme(scheme.Scheme)
 	Expect(err).NotTo(HaveOccurred())
+	err = examplev2.AddToScheme(scheme.Scheme)
+	Expect(err).NotTo(HaveOccurred())
 
 	// +kubebuilder:scaffold:scheme
 
@@ -88,14 +93,26 @@ var _ = BeforeSuite(func() {
 	Expect(k8sClient).NotTo(BeNil())
 
 	By("starting controller manager")
+	// envtest points the CRD conversion at the manager's webhook server,
+	// since JsonServers are stored as v2.
+	webhookOpts := &testEnv.WebhookInstallOptions
 	mgr, err := ctrl.NewManager(cfg, ctrl.Options{
 		Scheme: scheme.Scheme,
+		WebhookServer: webhook.NewServer(webhook.Options{
+			Host:    webhookOpts.LocalServingHost,
+			Port:    webhookOpts.LocalServingPort,
+			CertDir: webhookOpts.LocalServingCertDir,
+		}),
 	})
 	Expect(err).NotTo(HaveOccurred())
+	mgr.GetWebhookServer().Register("/convert",
+		conversion.NewWebhookHandler(mgr.GetScheme(), mgr.GetConverterRegistry()))
 
 	reconciler := &JsonServerReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:    mgr.GetClient(),
+		Scheme:    mgr.GetScheme(),
+		Recorder:  mgr.GetEventRecorder("jsonserver-controller"),
+		APIReader: mgr.GetAPIReader(),
 	}
 	Expect(reconciler.SetupWithManager(mgr)).To(Succeed())
 
@@ -0,0 +1,224 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dataformat
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	examplev1 "github.com/BlueTurtle-bytes/json-server/api/v1"
+)
+
+// numberRegexp matches CSV cells converted to numbers: JSON numbers, so
+// text such as 007 or 1e5x stays a string.
+var numberRegexp = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+
+// maxExactNumber is the largest magnitude up to which every integer has an
+// exact float64, the number type of json-server.
+const maxExactNumber = 1 << 53
+
+// DecodeCollection returns a collection as JSON. CSV and NDJSON become an
+// array with an item per row. Rows that cannot be converted are left out
+// and returned as row errors; an error means the collection as a whole
+// cannot be read. An empty CSV or NDJSON document is an empty collection.
+func DecodeCollection(data []byte, format examplev1.CollectionFormat) ([]byte, []examplev1.RowError, error) {
+	switch format {
+	case "", examplev1.CollectionFormatJSON:
+		data, err := decodeJSON(data)
+		return data, nil, err
+	case examplev1.CollectionFormatCSV:
+		return decodeCSV(data)
+	case examplev1.CollectionFormatNDJSON:
+		data, rowErrs := decodeNDJSON(data)
+		return data, rowErrs, nil
+	}
+	return nil, nil, fmt.Errorf("unknown collection format %q", format)
+}
+
+// IsCollection reports whether data is a JSON array or object, the values
+// json-server serves as a plural or singular resource.
+func IsCollection(data []byte) bool {
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return false
+	}
+	switch value.(type) {
+	case []any, map[string]any:
+		return true
+	}
+	return false
+}
+
+// decodeCSV converts CSV with a header row to an array of objects whose
+// keys are the header fields, in header order.
+func decodeCSV(data []byte) ([]byte, []examplev1.RowError, error) {
+	// Spreadsheets often start their exports with a byte order mark.
+	r := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	r.FieldsPerRecord = -1
+
+	header, err := r.Read()
+	switch {
+	case err == io.EOF:
+		return []byte("[]"), nil, nil
+	case err != nil:
+		return nil, nil, csvError(err)
+	}
+	seen := map[string]bool{}
+	for i, name := range header {
+		line, column := r.FieldPos(i)
+		switch {
+		case name == "":
+			return nil, nil, &SyntaxError{Line: line, Column: column, Msg: "empty field name in the header row"}
+		case seen[name]:
+			return nil, nil, &SyntaxError{Line: line, Column: column,
+				Msg: fmt.Sprintf("duplicate field name %q in the header row", name)}
+		}
+		seen[name] = true
+	}
+
+	var out bytes.Buffer
+	var rowErrs []examplev1.RowError
+	out.WriteByte('[')
+	items := 0
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		var parseErr *csv.ParseError
+		switch {
+		case errors.As(err, &parseErr):
+			rowErrs = append(rowErrs, examplev1.RowError{Line: int32(parseErr.StartLine), Message: parseErr.Err.Error()})
+			continue
+		case err != nil:
+			return nil, nil, err
+		}
+		if line, _ := r.FieldPos(0); len(record) != len(header) {
+			rowErrs = append(rowErrs, examplev1.RowError{Line: int32(line),
+				Message: fmt.Sprintf("has %d fields, the header row has %d", len(record), len(header))})
+			continue
+		}
+
+		if items > 0 {
+			out.WriteByte(',')
+		}
+		items++
+		out.WriteByte('{')
+		fields := 0
+		for i, value := range record {
+			if value == "" {
+				continue
+			}
+			if fields > 0 {
+				out.WriteByte(',')
+			}
+			fields++
+			writeString(&out, header[i])
+			out.WriteByte(':')
+			writeCell(&out, value)
+		}
+		out.WriteByte('}')
+	}
+	out.WriteByte(']')
+	return out.Bytes(), rowErrs, nil
+}
+
+// writeCell writes a CSV cell as a number or boolean when it reads as one,
+// and as a string otherwise.
+func writeCell(out *bytes.Buffer, value string) {
+	switch {
+	case strings.EqualFold(value, "true"):
+		out.WriteString("true")
+	case strings.EqualFold(value, "false"):
+		out.WriteString("false")
+	case isNumber(value):
+		out.WriteString(value)
+	default:
+		writeString(out, value)
+	}
+}
+
+// isNumber reports whether value is a JSON number that a float64 holds
+// without losing precision that matters. Values outside the float64 range,
+// and beyond 2^53 where integers such as long numeric IDs get rounded, stay
+// strings.
+func isNumber(value string) bool {
+	if !numberRegexp.MatchString(value) {
+		return false
+	}
+	if !strings.ContainsAny(value, ".eE") {
+		n, err := strconv.ParseInt(value, 10, 64)
+		return err == nil && n >= -maxExactNumber && n <= maxExactNumber
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.Abs(f) > maxExactNumber {
+		return false
+	}
+	// A number too small for a float64 reads as 0.
+	mantissa, _, _ := strings.Cut(strings.ToLower(value), "e")
+	return f != 0 || !strings.ContainsAny(mantissa, "123456789")
+}
+
+// csvError converts an error in the header row to a *SyntaxError.
+func csvError(err error) error {
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return &SyntaxError{Line: parseErr.Line, Column: parseErr.Column, Msg: parseErr.Err.Error()}
+	}
+	return err
+}
+
+// decodeNDJSON converts newline-delimited JSON objects to an array. Blank
+// lines are skipped.
+func decodeNDJSON(data []byte) ([]byte, []examplev1.RowError) {
+	var out bytes.Buffer
+	var rowErrs []examplev1.RowError
+	out.WriteByte('[')
+	items := 0
+	for i, line := range bytes.Split(data, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+
+		var item bytes.Buffer
+		if err := json.Compact(&item, line); err != nil {
+			rowErrs = append(rowErrs, examplev1.RowError{Line: int32(i + 1), Message: err.Error()})
+			continue
+		}
+		if line[0] != '{' {
+			rowErrs = append(rowErrs, examplev1.RowError{Line: int32(i + 1), Message: "is not a json object"})
+			continue
+		}
+
+		if items > 0 {
+			out.WriteByte(',')
+		}
+		items++
+		out.Write(item.Bytes())
+	}
+	out.WriteByte(']')
+	return out.Bytes(), rowErrs
+}
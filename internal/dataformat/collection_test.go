@@ -0,0 +1,128 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dataformat
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	examplev1 "github.com/BlueTurtle-bytes/json-server/api/v1"
+)
+
+var _ = Describe("DecodeCollection", func() {
+	It("should check JSON collections", func() {
+		out, rowErrs, err := DecodeCollection([]byte(`[{"id": 1}]`), "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rowErrs).To(BeEmpty())
+		Expect(string(out)).To(Equal(`[{"id": 1}]`))
+
+		_, _, err = DecodeCollection([]byte("[\n  {\"id\": 1},\n]"), examplev1.CollectionFormatJSON)
+		Expect(err).To(MatchError("line 3, column 1: invalid character ']' looking for beginning of value"))
+	})
+
+	DescribeTable("should tell collections from other JSON values",
+		func(data string, want bool) {
+			Expect(IsCollection([]byte(data))).To(Equal(want))
+		},
+		Entry("an array", `[{"id": 1}]`, true),
+		Entry("an object", `{"name": "typicode"}`, true),
+		Entry("a string", `"posts"`, false),
+		Entry("a number", `1`, false),
+		Entry("null", `null`, false),
+		Entry("invalid JSON", `[1,`, false),
+	)
+
+	It("should reject an unknown format", func() {
+		_, _, err := DecodeCollection([]byte(`[]`), "xlsx")
+		Expect(err).To(MatchError(`unknown collection format "xlsx"`))
+	})
+
+	DescribeTable("should convert CSV rows to objects",
+		func(data, want string) {
+			out, rowErrs, err := DecodeCollection([]byte(data), examplev1.CollectionFormatCSV)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(rowErrs).To(BeEmpty())
+			Expect(string(out)).To(Equal(want))
+		},
+		Entry("an empty document", "", `[]`),
+		Entry("only a header row", "id,name\n", `[]`),
+		Entry("fields in header order", "name,id\nAda,1\n", `[{"name":"Ada","id":1}]`),
+		Entry("a byte order mark", "\xef\xbb\xbfid,name\n1,Ada\n", `[{"id":1,"name":"Ada"}]`),
+		Entry("empty cells", "id,name,team\n1,,red\n2,Ada,\n", `[{"id":1,"team":"red"},{"id":2,"name":"Ada"}]`),
+		Entry("blank lines", "id\n\n1\n\n2\n", `[{"id":1},{"id":2}]`),
+		Entry("CRLF line endings", "id,name\r\n1,Ada\r\n", `[{"id":1,"name":"Ada"}]`),
+		Entry("quoted fields", "id,bio\n1,\"a, \"\"b\"\"\nc\"\n", `[{"id":1,"bio":"a, \"b\"\nc"}]`),
+		Entry("booleans", "a,b,c\ntrue,FALSE,yes\n", `[{"a":true,"b":false,"c":"yes"}]`),
+		Entry("numbers", "a,b,c,d\n-1,0.5,1e3,9007199254740992\n", `[{"a":-1,"b":0.5,"c":1e3,"d":9007199254740992}]`),
+		Entry("text that is not a JSON number", "a,b,c,d\n007,1e5x,.5,+1\n", `[{"a":"007","b":"1e5x","c":".5","d":"+1"}]`),
+		Entry("integers beyond 2^53", "a,b\n9007199254740993,-12345678901234567890\n",
+			`[{"a":"9007199254740993","b":"-12345678901234567890"}]`),
+		Entry("numbers outside the float64 range", "a,b,c\n1e400,-1e400,1e-400\n", `[{"a":"1e400","b":"-1e400","c":"1e-400"}]`),
+		Entry("fractions beyond 2^53", "a,b\n9007199254740993.5,1e16\n", `[{"a":"9007199254740993.5","b":"1e16"}]`),
+		Entry("zeros", "a,b\n0.0,0e-400\n", `[{"a":0.0,"b":0e-400}]`),
+	)
+
+	DescribeTable("should reject invalid CSV header rows",
+		func(data, want string) {
+			_, _, err := DecodeCollection([]byte(data), examplev1.CollectionFormatCSV)
+			var syntaxErr *SyntaxError
+			Expect(err).To(BeAssignableToTypeOf(syntaxErr))
+			Expect(err).To(MatchError(want))
+		},
+		Entry("an empty field name", "id,,name\n", "line 1, column 4: empty field name in the header row"),
+		Entry("a duplicate field name", "id,name,id\n", `line 1, column 9: duplicate field name "id" in the header row`),
+		Entry("a bare quote", "id,na\"me\n", `line 1, column 6: bare " in non-quoted-field`),
+		Entry("an unterminated quote", "\"id,name\n", `line 1, column 10: extraneous or missing " in quoted-field`),
+	)
+
+	It("should leave out CSV rows that cannot be read", func() {
+		out, rowErrs, err := DecodeCollection([]byte("id,name\n1,Ada\n2\n3,Bob,x\n4,\"B\"ob\n5,Eve\n"),
+			examplev1.CollectionFormatCSV)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(out)).To(Equal(`[{"id":1,"name":"Ada"},{"id":5,"name":"Eve"}]`))
+		Expect(rowErrs).To(Equal([]examplev1.RowError{
+			{Line: 3, Message: "has 1 fields, the header row has 2"},
+			{Line: 4, Message: "has 3 fields, the header row has 2"},
+			{Line: 5, Message: `extraneous or missing " in quoted-field`},
+		}))
+	})
+
+	DescribeTable("should convert NDJSON lines to items",
+		func(data, want string) {
+			out, rowErrs, err := DecodeCollection([]byte(data), examplev1.CollectionFormatNDJSON)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(rowErrs).To(BeEmpty())
+			Expect(string(out)).To(Equal(want))
+		},
+		Entry("an empty document", "", `[]`),
+		Entry("objects", "{\"id\": 1}\n{\"id\": 2}\n", `[{"id":1},{"id":2}]`),
+		Entry("blank lines", "\n{\"id\": 1}\n  \n\n{\"id\": 2}", `[{"id":1},{"id":2}]`),
+		Entry("CRLF line endings", "{\"id\": 1}\r\n{\"id\": 2}\r\n", `[{"id":1},{"id":2}]`),
+	)
+
+	It("should leave out NDJSON lines that cannot be read", func() {
+		out, rowErrs, err := DecodeCollection([]byte("{\"id\": 1}\n\n{\"id\": \n[1]\n\"a\"\n{\"id\": 2}\n"),
+			examplev1.CollectionFormatNDJSON)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(out)).To(Equal(`[{"id":1},{"id":2}]`))
+		Expect(rowErrs).To(Equal([]examplev1.RowError{
+			{Line: 3, Message: "unexpected end of JSON input"},
+			{Line: 4, Message: "is not a json object"},
+			{Line: 5, Message: "is not a json object"},
+		}))
+	})
+})
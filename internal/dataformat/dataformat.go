@@ -0,0 +1,155 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package dataformat decodes db.json written in one of the formats of
+// spec.dataFormat, and collections in one of the formats of
+// spec.collections[].format, into the JSON json-server reads. The webhook
+// and the controller both decode through it, so they accept the same
+// documents and report the same errors.
+package dataformat
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	examplev1 "github.com/BlueTurtle-bytes/json-server/api/v1"
+)
+
+// SyntaxError is a document that cannot be decoded. Line and Column are
+// 1-based; Column counts characters, not bytes, and is 0 when only the
+// line is known.
+type SyntaxError struct {
+	Line   int
+	Column int
+	Msg    string
+}
+
+func (e *SyntaxError) Error() string {
+	if e.Column == 0 {
+		return fmt.Sprintf("line %d: %s", e.Line, e.Msg)
+	}
+	return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Msg)
+}
+
+// Decode returns data as JSON. JSON is returned unchanged once it is known
+// to be valid; the other formats are converted to compact JSON. JSONC and
+// JSON5 keep the order of object keys, YAML sorts them. An empty format is
+// JSON. Errors in the document are a *SyntaxError.
+func Decode(data []byte, format examplev1.DataFormat) ([]byte, error) {
+	switch format {
+	case "", examplev1.DataFormatJSON:
+		return decodeJSON(data)
+	case examplev1.DataFormatJSON5:
+		return transcode(data, true)
+	case examplev1.DataFormatJSONC:
+		return transcode(data, false)
+	case examplev1.DataFormatYAML:
+		return decodeYAML(data)
+	}
+	return nil, fmt.Errorf("unknown data format %q", format)
+}
+
+// decodeJSON checks data with encoding/json, so the JSON format accepts
+// exactly what it did before formats existed.
+func decodeJSON(data []byte) ([]byte, error) {
+	var value any
+	err := json.Unmarshal(data, &value)
+	var syntaxErr *json.SyntaxError
+	switch {
+	case err == nil:
+		return data, nil
+	case errors.As(err, &syntaxErr):
+		// Offset counts the offending byte, except at the end of the input.
+		offset := int(syntaxErr.Offset)
+		if offset > 0 && !strings.HasPrefix(syntaxErr.Error(), "unexpected end") {
+			offset--
+		}
+		line, column := position(data, offset)
+		return nil, &SyntaxError{Line: line, Column: column, Msg: syntaxErr.Error()}
+	}
+	return nil, err
+}
+
+// yamlErrorRegexp finds the line in errors of sigs.k8s.io/yaml, which
+// report no column.
+var yamlErrorRegexp = regexp.MustCompile(`line (\d+): (.*)`)
+
+// decodeYAML converts a single YAML document to JSON. The document must not
+// be empty, and a second document after a --- marker is an error rather than
+// being dropped.
+func decodeYAML(data []byte) ([]byte, error) {
+	if line := secondDocument(data); line > 0 {
+		return nil, &SyntaxError{Line: line, Column: 1, Msg: "unexpected second document, expected a single document"}
+	}
+
+	out, err := yaml.YAMLToJSONStrict(data)
+	if err != nil {
+		if m := yamlErrorRegexp.FindStringSubmatch(err.Error()); m != nil {
+			line, _ := strconv.Atoi(m[1])
+			return nil, &SyntaxError{Line: line, Msg: m[2]}
+		}
+		return nil, &SyntaxError{Line: 1, Msg: strings.TrimPrefix(err.Error(), "yaml: ")}
+	}
+	if bytes.Equal(out, []byte("null")) {
+		return nil, &SyntaxError{Line: 1, Column: 1, Msg: "the document is empty or null"}
+	}
+	return out, nil
+}
+
+// secondDocument returns the line of the --- marker that starts a second
+// YAML document with content, or 0 if there is none. A marker before the
+// first content, and markers followed only by comments, start no document.
+func secondDocument(data []byte) int {
+	content, marker := false, 0
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if rest, ok := strings.CutPrefix(line, "---"); ok && (rest == "" || rest[0] == ' ' || rest[0] == '\t') {
+			if content {
+				marker = i + 1
+			}
+			line = rest
+		}
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || trimmed[0] == '#' || trimmed[0] == '%' || trimmed == "..." {
+			continue
+		}
+		if marker > 0 {
+			return marker
+		}
+		content = true
+	}
+	return 0
+}
+
+// position returns the line and column of the byte at offset.
+func position(data []byte, offset int) (line, column int) {
+	line, column = 1, 1
+	for _, r := range string(data[:min(offset, len(data))]) {
+		if r == '\n' {
+			line, column = line+1, 1
+			continue
+		}
+		column++
+	}
+	return line, column
+}
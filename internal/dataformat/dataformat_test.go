@@ -0,0 +1,80 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dataformat
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	examplev1 "github.com/BlueTurtle-bytes/json-server/api/v1"
+)
+
+var _ = Describe("Decode", func() {
+	It("should return valid JSON unchanged", func() {
+		data := "{\n  \"posts\": [ 1.0 ]\n}"
+		out, err := Decode([]byte(data), "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(out)).To(Equal(data))
+	})
+
+	It("should report JSON errors at their position", func() {
+		_, err := Decode([]byte("{\n  \"a\": 1,\n}"), examplev1.DataFormatJSON)
+		Expect(err).To(MatchError("line 3, column 1: invalid character '}' looking for beginning of object key string"))
+
+		_, err = Decode([]byte(`{"a": [1`), examplev1.DataFormatJSON)
+		Expect(err).To(MatchError("line 1, column 9: unexpected end of JSON input"))
+	})
+
+	It("should reject an unknown format", func() {
+		_, err := Decode([]byte(`{}`), "toml")
+		Expect(err).To(MatchError(`unknown data format "toml"`))
+	})
+
+	DescribeTable("should convert YAML to compact JSON",
+		func(data, want string) {
+			out, err := Decode([]byte(data), examplev1.DataFormatYAML)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(out)).To(Equal(want))
+		},
+		Entry("a mapping", "people:\n  - id: 1\n    name: Alice\n", `{"people":[{"id":1,"name":"Alice"}]}`),
+		Entry("sorted keys", "posts: []\ncomments: []\n", `{"comments":[],"posts":[]}`),
+		Entry("flow style", "{people: [1, 2]}", `{"people":[1,2]}`),
+		Entry("a leading marker", "# seed\n---\npeople: []\n", `{"people":[]}`),
+		Entry("a marker followed by comments", "people: []\n---\n# end\n", `{"people":[]}`),
+		Entry("a document end marker", "people: []\n...\n", `{"people":[]}`),
+		Entry("markers in strings", "people:\n  - |\n    ---\n    text\n", `{"people":["---\ntext\n"]}`),
+	)
+
+	DescribeTable("should reject invalid YAML",
+		func(data, want string) {
+			_, err := Decode([]byte(data), examplev1.DataFormatYAML)
+			var syntaxErr *SyntaxError
+			Expect(err).To(BeAssignableToTypeOf(syntaxErr))
+			Expect(err).To(MatchError(want))
+		},
+		Entry("a syntax error", "people: [1, 2\n", "line 1: did not find expected ',' or ']'"),
+		Entry("a tab indent", "people:\n\t- 1\n", "line 2: found character that cannot start any token"),
+		Entry("a duplicate key", "people: []\npeople: []\n", `line 2: key "people" already set in map`),
+		Entry("empty input", "", "line 1, column 1: the document is empty or null"),
+		Entry("only comments", "# seed\n", "line 1, column 1: the document is empty or null"),
+		Entry("a null document", "~\n", "line 1, column 1: the document is empty or null"),
+		Entry("a second document", "people: []\n---\nposts: []\n",
+			"line 2, column 1: unexpected second document, expected a single document"),
+		Entry("a second document on the marker line", "--- {people: []}\n--- {posts: []}\n",
+			"line 2, column 1: unexpected second document, expected a single document"),
+	)
+})
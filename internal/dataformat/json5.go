@@ -0,0 +1,485 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dataformat
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// maxDepth limits the nesting of arrays and objects, as encoding/json does.
+const maxDepth = 10000
+
+// transcoder converts JSONC, and with json5 set JSON5, to compact JSON in
+// a single pass. JSONC is JSON with comments and trailing commas; JSON5
+// adds the rest of https://spec.json5.org.
+type transcoder struct {
+	data  []byte
+	pos   int
+	json5 bool
+	out   bytes.Buffer
+}
+
+func transcode(data []byte, json5 bool) ([]byte, error) {
+	t := &transcoder{data: data, json5: json5}
+	if err := t.skipSpace(); err != nil {
+		return nil, err
+	}
+	if err := t.value(0); err != nil {
+		return nil, err
+	}
+	if err := t.skipSpace(); err != nil {
+		return nil, err
+	}
+	if t.pos < len(t.data) {
+		return nil, t.errorf("unexpected %s after the top-level value", t.describe())
+	}
+	return t.out.Bytes(), nil
+}
+
+// errorf returns a *SyntaxError at the current position.
+func (t *transcoder) errorf(format string, args ...any) error {
+	line, column := position(t.data, t.pos)
+	return &SyntaxError{Line: line, Column: column, Msg: fmt.Sprintf(format, args...)}
+}
+
+// peek returns the next character, or utf8.RuneError and 0 at the end.
+func (t *transcoder) peek() (rune, int) {
+	if t.pos >= len(t.data) {
+		return utf8.RuneError, 0
+	}
+	return utf8.DecodeRune(t.data[t.pos:])
+}
+
+// describe names the next character for error messages.
+func (t *transcoder) describe() string {
+	r, size := t.peek()
+	if size == 0 {
+		return "end of input"
+	}
+	return fmt.Sprintf("character %q", r)
+}
+
+// skipSpace skips whitespace and comments.
+func (t *transcoder) skipSpace() error {
+	for t.pos < len(t.data) {
+		r, size := t.peek()
+		switch {
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			t.pos += size
+		case t.json5 && (unicode.IsSpace(r) || r == '\uFEFF'):
+			t.pos += size
+		case bytes.HasPrefix(t.data[t.pos:], []byte("//")):
+			end := bytes.IndexByte(t.data[t.pos:], '\n')
+			if end < 0 {
+				t.pos = len(t.data)
+			} else {
+				t.pos += end + 1
+			}
+		case bytes.HasPrefix(t.data[t.pos:], []byte("/*")):
+			end := bytes.Index(t.data[t.pos+2:], []byte("*/"))
+			if end < 0 {
+				return t.errorf("unterminated comment")
+			}
+			t.pos += end + 4
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+// value transcodes the value at the current position.
+func (t *transcoder) value(depth int) error {
+	r, _ := t.peek()
+	switch {
+	case r == '{' || r == '[':
+		if depth >= maxDepth {
+			return t.errorf("exceeded max depth of %d", maxDepth)
+		}
+		if r == '{' {
+			return t.object(depth + 1)
+		}
+		return t.array(depth + 1)
+	case r == '"' || (t.json5 && r == '\''):
+		s, err := t.string()
+		if err != nil {
+			return err
+		}
+		writeString(&t.out, s)
+		return nil
+	case r == '-' || (r >= '0' && r <= '9') || (t.json5 && (r == '+' || r == '.' || r == 'I' || r == 'N')):
+		return t.number()
+	}
+	for _, literal := range []string{"true", "false", "null"} {
+		if bytes.HasPrefix(t.data[t.pos:], []byte(literal)) {
+			t.out.WriteString(literal)
+			t.pos += len(literal)
+			return nil
+		}
+	}
+	return t.errorf("unexpected %s, expected a value", t.describe())
+}
+
+func (t *transcoder) object(depth int) error {
+	t.pos++
+	t.out.WriteByte('{')
+	for first := true; ; first = false {
+		if err := t.skipSpace(); err != nil {
+			return err
+		}
+		// A trailing comma is allowed, but not an empty member.
+		if r, _ := t.peek(); r == '}' {
+			t.pos++
+			t.out.WriteByte('}')
+			return nil
+		}
+		if !first {
+			t.out.WriteByte(',')
+		}
+
+		key, err := t.key()
+		if err != nil {
+			return err
+		}
+		writeString(&t.out, key)
+		if err := t.skipSpace(); err != nil {
+			return err
+		}
+		if r, _ := t.peek(); r != ':' {
+			return t.errorf("unexpected %s, expected ':' after an object key", t.describe())
+		}
+		t.pos++
+		t.out.WriteByte(':')
+		if err := t.skipSpace(); err != nil {
+			return err
+		}
+		if err := t.value(depth); err != nil {
+			return err
+		}
+		if err := t.skipSpace(); err != nil {
+			return err
+		}
+
+		switch r, _ := t.peek(); r {
+		case ',':
+			t.pos++
+		case '}':
+			t.pos++
+			t.out.WriteByte('}')
+			return nil
+		default:
+			return t.errorf("unexpected %s, expected ',' or '}' after an object value", t.describe())
+		}
+	}
+}
+
+func (t *transcoder) array(depth int) error {
+	t.pos++
+	t.out.WriteByte('[')
+	for first := true; ; first = false {
+		if err := t.skipSpace(); err != nil {
+			return err
+		}
+		if r, _ := t.peek(); r == ']' {
+			t.pos++
+			t.out.WriteByte(']')
+			return nil
+		}
+		if !first {
+			t.out.WriteByte(',')
+		}
+
+		if err := t.value(depth); err != nil {
+			return err
+		}
+		if err := t.skipSpace(); err != nil {
+			return err
+		}
+
+		switch r, _ := t.peek(); r {
+		case ',':
+			t.pos++
+		case ']':
+			t.pos++
+			t.out.WriteByte(']')
+			return nil
+		default:
+			return t.errorf("unexpected %s, expected ',' or ']' after an array element", t.describe())
+		}
+	}
+}
+
+// key reads an object key: a string or, in JSON5, an identifier.
+func (t *transcoder) key() (string, error) {
+	r, _ := t.peek()
+	if r == '"' || (t.json5 && r == '\'') {
+		return t.string()
+	}
+	if !t.json5 || !isIdentifierStart(r) {
+		return "", t.errorf("unexpected %s, expected an object key", t.describe())
+	}
+
+	start := t.pos
+	for t.pos < len(t.data) {
+		r, size := t.peek()
+		if !isIdentifierStart(r) && !unicode.IsDigit(r) && !unicode.In(r, unicode.Mn, unicode.Mc, unicode.Pc) {
+			break
+		}
+		t.pos += size
+	}
+	return string(t.data[start:t.pos]), nil
+}
+
+func isIdentifierStart(r rune) bool {
+	return r == '$' || r == '_' || unicode.IsLetter(r) || unicode.Is(unicode.Nl, r)
+}
+
+// string reads a string in double or, in JSON5, single quotes.
+func (t *transcoder) string() (string, error) {
+	quote, _ := t.peek()
+	t.pos++
+
+	var s strings.Builder
+	for {
+		r, size := t.peek()
+		switch {
+		case size == 0:
+			return "", t.errorf("unterminated string")
+		case r == quote:
+			t.pos++
+			return s.String(), nil
+		case r == '\\':
+			if err := t.escape(&s); err != nil {
+				return "", err
+			}
+		case r == '\n' || r == '\r':
+			return "", t.errorf("unterminated string")
+		case r < 0x20 && !t.json5:
+			return "", t.errorf("invalid control character %q in string", r)
+		default:
+			s.WriteRune(r)
+			t.pos += size
+		}
+	}
+}
+
+// escapes maps single-character escapes to their character. \' and \v
+// are JSON5 only.
+var escapes = map[rune]rune{
+	'"': '"', '\\': '\\', '/': '/', 'b': '\b', 'f': '\f', 'n': '\n', 'r': '\r', 't': '\t',
+	'\'': '\'', 'v': '\v',
+}
+
+// escape reads the escape sequence at the current position into s.
+func (t *transcoder) escape(s *strings.Builder) error {
+	t.pos++
+	r, size := t.peek()
+	if size == 0 {
+		return t.errorf("unterminated string")
+	}
+
+	if c, ok := escapes[r]; ok && (t.json5 || r != '\'' && r != 'v') {
+		s.WriteRune(c)
+		t.pos += size
+		return nil
+	}
+
+	switch {
+	case r == 'u':
+		t.pos++
+		unit, err := t.hex(4)
+		if err != nil {
+			return err
+		}
+		c := rune(unit)
+		if utf16.IsSurrogate(c) && bytes.HasPrefix(t.data[t.pos:], []byte(`\u`)) {
+			// A surrogate pair is two escapes.
+			save := t.pos
+			t.pos += 2
+			low, err := t.hex(4)
+			if err != nil {
+				return err
+			}
+			if pair := utf16.DecodeRune(c, rune(low)); pair != unicode.ReplacementChar {
+				c = pair
+			} else {
+				t.pos = save
+			}
+		}
+		if utf16.IsSurrogate(c) {
+			c = unicode.ReplacementChar
+		}
+		s.WriteRune(c)
+		return nil
+	case !t.json5:
+	case r == 'x':
+		t.pos++
+		b, err := t.hex(2)
+		if err != nil {
+			return err
+		}
+		s.WriteRune(rune(b))
+		return nil
+	case r == '0' && !t.followedByDigit():
+		t.pos++
+		s.WriteByte(0)
+		return nil
+	case r == '\r':
+		// A line continuation is dropped from the string.
+		t.pos++
+		if r, _ := t.peek(); r == '\n' {
+			t.pos++
+		}
+		return nil
+	case r == '\n' || r == '\u2028' || r == '\u2029':
+		t.pos += size
+		return nil
+	case r < '0' || r > '9':
+		// Any other character escapes to itself.
+		s.WriteRune(r)
+		t.pos += size
+		return nil
+	}
+	return t.errorf("invalid escape sequence '\\%c' in string", r)
+}
+
+// followedByDigit reports whether the character after the next one is a
+// digit.
+func (t *transcoder) followedByDigit() bool {
+	return t.pos+1 < len(t.data) && t.data[t.pos+1] >= '0' && t.data[t.pos+1] <= '9'
+}
+
+// hex reads n hexadecimal digits.
+func (t *transcoder) hex(n int) (uint64, error) {
+	if t.pos+n > len(t.data) {
+		return 0, t.errorf("invalid escape sequence in string")
+	}
+	v, err := strconv.ParseUint(string(t.data[t.pos:t.pos+n]), 16, 32)
+	if err != nil {
+		return 0, t.errorf("invalid escape sequence in string")
+	}
+	t.pos += n
+	return v, nil
+}
+
+// number transcodes a number. JSON5 signs, hexadecimal numbers and leading
+// or trailing decimal points become plain JSON numbers; Infinity and NaN
+// have no JSON form and are rejected.
+func (t *transcoder) number() error {
+	start := t.pos
+	sign := ""
+	if r, _ := t.peek(); r == '-' || (t.json5 && r == '+') {
+		if r == '-' {
+			sign = "-"
+		}
+		t.pos++
+	}
+
+	if t.json5 {
+		for _, name := range []string{"Infinity", "NaN"} {
+			if bytes.HasPrefix(t.data[t.pos:], []byte(name)) {
+				return t.errorf("%s cannot be represented in JSON", name)
+			}
+		}
+		if bytes.HasPrefix(t.data[t.pos:], []byte("0x")) || bytes.HasPrefix(t.data[t.pos:], []byte("0X")) {
+			t.pos += 2
+			digits := t.digits(isHexDigit)
+			n, ok := new(big.Int).SetString(digits, 16)
+			if !ok {
+				return t.errorf("invalid hexadecimal number")
+			}
+			t.out.WriteString(sign + n.String())
+			return nil
+		}
+	}
+
+	integer := t.digits(isDigit)
+	if len(integer) > 1 && integer[0] == '0' {
+		t.pos = start
+		return t.errorf("invalid number: leading zero")
+	}
+	fraction, point := "", false
+	if r, _ := t.peek(); r == '.' {
+		t.pos++
+		point = true
+		fraction = t.digits(isDigit)
+	}
+	switch {
+	case integer == "" && (!t.json5 || fraction == ""):
+		return t.errorf("invalid number: expected a digit")
+	case point && fraction == "" && !t.json5:
+		return t.errorf("invalid number: expected a digit after the decimal point")
+	}
+
+	exponent := ""
+	if r, _ := t.peek(); r == 'e' || r == 'E' {
+		t.pos++
+		exponent = "e"
+		if r, _ := t.peek(); r == '+' || r == '-' {
+			t.pos++
+			exponent += string(r)
+		}
+		digits := t.digits(isDigit)
+		if digits == "" {
+			return t.errorf("invalid number: expected a digit in the exponent")
+		}
+		exponent += digits
+	}
+
+	if integer == "" {
+		integer = "0"
+	}
+	if fraction != "" {
+		fraction = "." + fraction
+	}
+	t.out.WriteString(sign + integer + fraction + exponent)
+	return nil
+}
+
+// digits reads a run of digits accepted by is.
+func (t *transcoder) digits(is func(byte) bool) string {
+	start := t.pos
+	for t.pos < len(t.data) && is(t.data[t.pos]) {
+		t.pos++
+	}
+	return string(t.data[start:t.pos])
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isHexDigit(c byte) bool {
+	return isDigit(c) || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+// writeString writes s as a JSON string without escaping HTML characters,
+// which json-server serves unchanged.
+func writeString(out *bytes.Buffer, s string) {
+	enc := json.NewEncoder(out)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s)
+	out.Truncate(out.Len() - 1) // Encode adds a newline
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dataformat
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	examplev1 "github.com/BlueTurtle-bytes/json-server/api/v1"
+)
+
+var _ = Describe("JSONC and JSON5", func() {
+	DescribeTable("should convert to compact JSON",
+		func(format examplev1.DataFormat, data, want string) {
+			out, err := Decode([]byte(data), format)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(out)).To(Equal(want))
+		},
+		Entry("jsonc line comments", examplev1.DataFormatJSONC,
+			"// seed\n{\"a\": 1 // one\n}", `{"a":1}`),
+		Entry("jsonc block comments", examplev1.DataFormatJSONC,
+			"/* seed */ {\"a\": /* one */ 1}", `{"a":1}`),
+		Entry("jsonc trailing commas", examplev1.DataFormatJSONC,
+			`{"a": [1, 2,], "b": {},}`, `{"a":[1,2],"b":{}}`),
+		Entry("jsonc key order", examplev1.DataFormatJSONC,
+			`{"posts": [], "comments": []}`, `{"posts":[],"comments":[]}`),
+		Entry("jsonc escapes", examplev1.DataFormatJSONC,
+			`{"a": "<\"\u00e9\ud83d\ude00\">"}`, `{"a":"<\"é😀\">"}`),
+		Entry("json5 unquoted keys", examplev1.DataFormatJSON5,
+			`{people: [], $ref: 1, _id: 2}`, `{"people":[],"$ref":1,"_id":2}`),
+		Entry("json5 single-quoted strings", examplev1.DataFormatJSON5,
+			`{'a': 'it\'s "quoted"'}`, `{"a":"it's \"quoted\""}`),
+		Entry("json5 line continuations", examplev1.DataFormatJSON5,
+			"{a: 'type\\\ncode'}", `{"a":"typecode"}`),
+		Entry("json5 hexadecimal numbers", examplev1.DataFormatJSON5,
+			`[0x1F, -0XfF, 0xFFFFFFFFFFFFFFFFFF]`, `[31,-255,4722366482869645213695]`),
+		Entry("json5 decimal points and signs", examplev1.DataFormatJSON5,
+			`[.5, 5., +1, -.5e2]`, `[0.5,5,1,-0.5e2]`),
+		Entry("json5 whitespace", examplev1.DataFormatJSON5,
+			"\uFEFF{a:\u00a01}", `{"a":1}`),
+	)
+
+	DescribeTable("should report errors at their position",
+		func(format examplev1.DataFormat, data, want string) {
+			_, err := Decode([]byte(data), format)
+			var syntaxErr *SyntaxError
+			Expect(err).To(BeAssignableToTypeOf(syntaxErr))
+			Expect(err).To(MatchError(want))
+		},
+		Entry("jsonc unquoted key", examplev1.DataFormatJSONC,
+			`{people: []}`, "line 1, column 2: unexpected character 'p', expected an object key"),
+		Entry("jsonc single quotes", examplev1.DataFormatJSONC,
+			`['a']`, `line 1, column 2: unexpected character '\'', expected a value`),
+		Entry("jsonc hexadecimal number", examplev1.DataFormatJSONC,
+			`[0x1F]`, "line 1, column 3: unexpected character 'x', expected ',' or ']' after an array element"),
+		Entry("jsonc unterminated comment", examplev1.DataFormatJSONC,
+			"{\n  /* seed", "line 2, column 3: unterminated comment"),
+		Entry("jsonc empty member", examplev1.DataFormatJSONC,
+			`[1,,2]`, "line 1, column 4: unexpected character ',', expected a value"),
+		Entry("json5 Infinity", examplev1.DataFormatJSON5,
+			"{\n  max: Infinity\n}", "line 2, column 8: Infinity cannot be represented in JSON"),
+		Entry("json5 negative Infinity", examplev1.DataFormatJSON5,
+			`[-Infinity]`, "line 1, column 3: Infinity cannot be represented in JSON"),
+		Entry("json5 NaN", examplev1.DataFormatJSON5,
+			"{\n  count: NaN\n}", "line 2, column 10: NaN cannot be represented in JSON"),
+		Entry("json5 leading zero", examplev1.DataFormatJSON5,
+			`[01]`, "line 1, column 2: invalid number: leading zero"),
+		Entry("json5 unterminated string", examplev1.DataFormatJSON5,
+			"{a: 'x\n}", "line 1, column 7: unterminated string"),
+		Entry("json5 columns in characters", examplev1.DataFormatJSON5,
+			`{"é": ?}`, "line 1, column 7: unexpected character '?', expected a value"),
+		Entry("json5 text after the value", examplev1.DataFormatJSON5,
+			"{}\n{}", "line 2, column 1: unexpected character '{' after the top-level value"),
+		Entry("json5 empty input", examplev1.DataFormatJSON5,
+			"  // nothing\n", "line 2, column 1: unexpected end of input, expected a value"),
+	)
+})
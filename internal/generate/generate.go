@@ -0,0 +1,318 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package generate renders the synthetic collections of spec.generate.
+//
+// A field template is text with placeholders, e.g. "{{name}}",
+// "{{int 18 90}}" or "user-{{index}}@example.com". A template that is a
+// single placeholder keeps the type of its value, so "{{int 1 5}}" renders
+// a number; any other template renders a string.
+package generate
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"maps"
+	"math"
+	"math/rand/v2"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	examplev1 "github.com/BlueTurtle-bytes/json-server/api/v1"
+)
+
+// placeholderRegexp matches {{kind arg ...}}.
+var placeholderRegexp = regexp.MustCompile(`\{\{\s*([A-Za-z]+)((?:\s+[^\s{}]+)*)\s*\}\}`)
+
+// Default range of {{date}}.
+var (
+	defaultFrom = time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+	defaultTo   = time.Date(2025, time.December, 31, 0, 0, 0, 0, time.UTC)
+)
+
+// Template is a parsed field template.
+type Template struct {
+	// literals surround the placeholders: literals[i] precedes
+	// placeholders[i], and the last literal follows the last placeholder.
+	literals     []string
+	placeholders []placeholder
+}
+
+type placeholder struct {
+	kind string
+	args []string
+
+	// Parsed arguments of int, float and date.
+	min, max  int64
+	low, high float64
+	from, to  time.Time
+}
+
+// Parse parses a field template and checks its placeholders.
+func Parse(template string) (*Template, error) {
+	t := &Template{}
+	rest := template
+	for {
+		loc := placeholderRegexp.FindStringSubmatchIndex(rest)
+		if loc == nil {
+			break
+		}
+		t.literals = append(t.literals, rest[:loc[0]])
+		p, err := parsePlaceholder(rest[loc[2]:loc[3]], strings.Fields(rest[loc[4]:loc[5]]))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", rest[loc[0]:loc[1]], err)
+		}
+		t.placeholders = append(t.placeholders, p)
+		rest = rest[loc[1]:]
+	}
+	if strings.Contains(rest, "{{") {
+		return nil, fmt.Errorf("unterminated or malformed placeholder in %q", template)
+	}
+	t.literals = append(t.literals, rest)
+	return t, nil
+}
+
+func parsePlaceholder(kind string, args []string) (placeholder, error) {
+	p := placeholder{kind: kind, args: args}
+
+	switch kind {
+	case "index", "name", "firstName", "lastName", "email", "uuid", "word", "sentence", "bool":
+		if len(args) != 0 {
+			return p, fmt.Errorf("%s takes no arguments", kind)
+		}
+	case "int":
+		if len(args) != 2 {
+			return p, fmt.Errorf("%s takes a minimum and a maximum", kind)
+		}
+		var err error
+		if p.min, err = parseInt(args[0]); err != nil {
+			return p, fmt.Errorf("minimum %q %w", args[0], err)
+		}
+		if p.max, err = parseInt(args[1]); err != nil {
+			return p, fmt.Errorf("maximum %q %w", args[1], err)
+		}
+		if p.max < p.min {
+			return p, fmt.Errorf("maximum is less than minimum")
+		}
+	case "float":
+		if len(args) != 2 {
+			return p, fmt.Errorf("%s takes a minimum and a maximum", kind)
+		}
+		var err error
+		if p.low, err = strconv.ParseFloat(args[0], 64); err != nil {
+			return p, fmt.Errorf("minimum %q is not a number", args[0])
+		}
+		if p.high, err = strconv.ParseFloat(args[1], 64); err != nil {
+			return p, fmt.Errorf("maximum %q is not a number", args[1])
+		}
+		if p.high < p.low {
+			return p, fmt.Errorf("maximum is less than minimum")
+		}
+	case "date":
+		p.from, p.to = defaultFrom, defaultTo
+		switch len(args) {
+		case 0:
+		case 2:
+			var err error
+			if p.from, err = time.Parse(time.DateOnly, args[0]); err != nil {
+				return p, fmt.Errorf("%q is not a date like 2024-01-31", args[0])
+			}
+			if p.to, err = time.Parse(time.DateOnly, args[1]); err != nil {
+				return p, fmt.Errorf("%q is not a date like 2024-01-31", args[1])
+			}
+			if p.to.Before(p.from) {
+				return p, fmt.Errorf("%s is before %s", args[1], args[0])
+			}
+		default:
+			return p, fmt.Errorf("date takes no arguments or a first and a last day")
+		}
+	case "pick":
+		if len(args) == 0 {
+			return p, fmt.Errorf("pick takes the values to choose from")
+		}
+	case "ref":
+		if len(args) != 1 {
+			return p, fmt.Errorf("ref takes a collection name")
+		}
+	default:
+		return p, fmt.Errorf("unknown placeholder %q", kind)
+	}
+	return p, nil
+}
+
+// parseInt parses a bound of int, also in exponent notation such as 1e6.
+func parseInt(s string) (int64, error) {
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return n, nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	switch {
+	case err != nil:
+		return 0, errors.New("is not a number")
+	case f != math.Trunc(f):
+		return 0, errors.New("is not an integer")
+	// float64(math.MaxInt64) rounds up to 2^63, which is out of range.
+	case f < math.MinInt64 || f >= math.MaxInt64:
+		return 0, errors.New("is outside the int64 range")
+	}
+	return int64(f), nil
+}
+
+// Refs returns the collections the template references.
+func (t *Template) Refs() []string {
+	var refs []string
+	for _, p := range t.placeholders {
+		if p.kind == "ref" {
+			refs = append(refs, p.args[0])
+		}
+	}
+	return refs
+}
+
+// Items generates the items of a collection. Items get the ids 1 to
+// spec.Count in idField. ids holds the ids of the collections the templates
+// may reference. The same spec and ids always give the same items.
+func Items(spec examplev1.GenerateSpec, idField string, ids map[string][]any) ([]map[string]any, error) {
+	fields := slices.Sorted(maps.Keys(spec.Fields))
+	templates := make(map[string]*Template, len(fields))
+	for _, name := range fields {
+		t, err := Parse(spec.Fields[name])
+		if err != nil {
+			return nil, fmt.Errorf("field %q: %w", name, err)
+		}
+		for _, ref := range t.Refs() {
+			if len(ids[ref]) == 0 {
+				return nil, fmt.Errorf("field %q: collection %q has no items to reference", name, ref)
+			}
+		}
+		templates[name] = t
+	}
+
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(spec.Name))
+	rng := rand.New(rand.NewPCG(uint64(spec.Seed), h.Sum64()))
+
+	items := make([]map[string]any, spec.Count)
+	for i := range items {
+		item := make(map[string]any, len(fields)+1)
+		item[idField] = i + 1
+		for _, name := range fields {
+			if name != idField {
+				item[name] = templates[name].render(rng, i, ids)
+			}
+		}
+		items[i] = item
+	}
+	return items, nil
+}
+
+// render renders the template for the index-th item.
+func (t *Template) render(rng *rand.Rand, index int, ids map[string][]any) any {
+	if len(t.placeholders) == 1 && t.literals[0] == "" && t.literals[1] == "" {
+		return t.placeholders[0].value(rng, index, ids)
+	}
+
+	var b strings.Builder
+	for i, p := range t.placeholders {
+		b.WriteString(t.literals[i])
+		fmt.Fprint(&b, p.value(rng, index, ids))
+	}
+	b.WriteString(t.literals[len(t.literals)-1])
+	return b.String()
+}
+
+func (p placeholder) value(rng *rand.Rand, index int, ids map[string][]any) any {
+	switch p.kind {
+	case "index":
+		return index + 1
+	case "name":
+		return pick(rng, firstNames) + " " + pick(rng, lastNames)
+	case "firstName":
+		return pick(rng, firstNames)
+	case "lastName":
+		return pick(rng, lastNames)
+	case "email":
+		return fmt.Sprintf("%s.%s%d@example.com",
+			strings.ToLower(pick(rng, firstNames)), strings.ToLower(pick(rng, lastNames)), index+1)
+	case "uuid":
+		return fmt.Sprintf("%08x-%04x-4%03x-8%03x-%012x", rng.Uint32(), rng.IntN(1<<16),
+			rng.IntN(1<<12), rng.IntN(1<<12), rng.Int64N(1<<48))
+	case "word":
+		return pick(rng, words)
+	case "sentence":
+		n := 4 + rng.IntN(6)
+		sentence := make([]string, n)
+		for i := range sentence {
+			sentence[i] = pick(rng, words)
+		}
+		return strings.ToUpper(sentence[0][:1]) + strings.Join(sentence, " ")[1:] + "."
+	case "bool":
+		return rng.IntN(2) == 1
+	case "int":
+		return IntBetween(rng, p.min, p.max)
+	case "float":
+		// Weighting the bounds cannot overflow, unlike their difference.
+		r := rng.Float64()
+		v := p.low*(1-r) + p.high*r
+		if math.Abs(v) < 1e15 {
+			v = math.Round(v*100) / 100
+		}
+		return v
+	case "date":
+		// Durations end at about 292 years, Unix seconds do not.
+		days := (p.to.Unix() - p.from.Unix()) / (24 * 60 * 60)
+		return p.from.AddDate(0, 0, int(IntBetween(rng, 0, days))).Format(time.DateOnly)
+	case "pick":
+		return pick(rng, p.args)
+	case "ref":
+		return pick(rng, ids[p.args[0]])
+	}
+	return nil
+}
+
+// IntBetween returns a number in [low, high]. The span is computed in
+// uint64, where it fits even for the full int64 range.
+func IntBetween(rng *rand.Rand, low, high int64) int64 {
+	span := uint64(high) - uint64(low)
+	if span == math.MaxUint64 {
+		return int64(rng.Uint64())
+	}
+	return low + int64(rng.Uint64N(span+1))
+}
+
+func pick[T any](rng *rand.Rand, values []T) T {
+	return values[rng.IntN(len(values))]
+}
+
+var firstNames = []string{
+	"Ada", "Alan", "Barbara", "Brian", "Claude", "Dennis", "Donald", "Edsger", "Frances", "Grace",
+	"Guido", "Hedy", "John", "Ken", "Linus", "Margaret", "Niklaus", "Radia", "Rob", "Tim",
+}
+
+var lastNames = []string{
+	"Allen", "Berners-Lee", "Dijkstra", "Hamilton", "Hopper", "Kernighan", "Knuth", "Lamarr", "Liskov", "Lovelace",
+	"McCarthy", "Perlman", "Pike", "Ritchie", "Rossum", "Shannon", "Thompson", "Torvalds", "Turing", "Wirth",
+}
+
+var words = []string{
+	"alpha", "bridge", "cloud", "delta", "engine", "falcon", "garden", "harbor", "island", "jungle",
+	"kernel", "lantern", "meadow", "nebula", "orbit", "pixel", "quartz", "river", "signal", "timber",
+	"umbrella", "velvet", "willow", "yonder", "zephyr",
+}
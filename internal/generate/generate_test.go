@@ -0,0 +1,180 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generate
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	examplev1 "github.com/BlueTurtle-bytes/json-server/api/v1"
+)
+
+var _ = Describe("Generate", func() {
+	Context("When parsing templates", func() {
+		DescribeTable("should accept valid placeholders",
+			func(template string, refs []string) {
+				t, err := Parse(template)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(t.Refs()).To(Equal(refs))
+			},
+			Entry("plain text", "hello", nil),
+			Entry("a single placeholder", "{{name}}", nil),
+			Entry("text around placeholders", "user-{{index}}@{{word}}.example.com", nil),
+			Entry("spaces inside braces", "{{ int 1 5 }}", nil),
+			Entry("int in exponent notation", "{{int 0 1e6}}", nil),
+			Entry("the full int64 range", "{{int -9223372036854775808 9223372036854775807}}", nil),
+			Entry("float bounds", "{{float -1.5 1e300}}", nil),
+			Entry("dates", "{{date 2024-01-01 2024-12-31}}", nil),
+			Entry("references", "{{ref users}}-{{ref posts}}", []string{"users", "posts"}),
+		)
+
+		DescribeTable("should reject invalid placeholders",
+			func(template, message string) {
+				_, err := Parse(template)
+				Expect(err).To(MatchError(ContainSubstring(message)))
+			},
+			Entry("an unknown kind", "{{color}}", `unknown placeholder "color"`),
+			Entry("arguments to name", "{{name x}}", "name takes no arguments"),
+			Entry("a missing maximum", "{{int 1}}", "int takes a minimum and a maximum"),
+			Entry("a non-numeric bound", "{{int a 5}}", `minimum "a" is not a number`),
+			Entry("a fractional int bound", "{{int 1 2.5}}", `maximum "2.5" is not an integer`),
+			Entry("an int bound above int64", "{{int 0 1e19}}", `maximum "1e19" is outside the int64 range`),
+			Entry("an int bound one above int64", "{{int 0 9223372036854775808}}", "is outside the int64 range"),
+			Entry("an int bound below int64", "{{int -1e19 0}}", `minimum "-1e19" is outside the int64 range`),
+			Entry("reversed int bounds", "{{int 90 18}}", "maximum is less than minimum"),
+			Entry("reversed float bounds", "{{float 2 1}}", "maximum is less than minimum"),
+			Entry("a malformed date", "{{date 2024-13-01 2024-12-31}}", "is not a date"),
+			Entry("reversed dates", "{{date 2024-12-31 2024-01-01}}", "is before"),
+			Entry("a pick without values", "{{pick}}", "pick takes the values to choose from"),
+			Entry("a ref without a collection", "{{ref}}", "ref takes a collection name"),
+			Entry("an unterminated placeholder", "{{name", "unterminated or malformed placeholder"),
+		)
+	})
+
+	Context("When rendering values", func() {
+		It("should stay within wide int ranges", func() {
+			rng := rand.New(rand.NewPCG(1, 2))
+			for _, bounds := range [][2]int64{
+				{math.MinInt64, math.MaxInt64},
+				{0, math.MaxInt64},
+				{-9e18, 9e18},
+				{math.MaxInt64, math.MaxInt64},
+				{-3, 3},
+			} {
+				for range 100 {
+					Expect(IntBetween(rng, bounds[0], bounds[1])).To(And(
+						BeNumerically(">=", bounds[0]), BeNumerically("<=", bounds[1])))
+				}
+			}
+		})
+
+		It("should stay within wide float ranges", func() {
+			t, err := Parse("{{float -1e308 1e308}}")
+			Expect(err).NotTo(HaveOccurred())
+			rng := rand.New(rand.NewPCG(1, 2))
+			for range 100 {
+				v := t.render(rng, 0, nil).(float64)
+				Expect(math.IsInf(v, 0)).To(BeFalse())
+			}
+		})
+
+		It("should stay within wide date ranges", func() {
+			t, err := Parse("{{date 1000-01-01 2100-01-01}}")
+			Expect(err).NotTo(HaveOccurred())
+			rng := rand.New(rand.NewPCG(1, 2))
+			var years []int
+			for range 100 {
+				date, err := time.Parse(time.DateOnly, t.render(rng, 0, nil).(string))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(date.Year()).To(And(BeNumerically(">=", 1000), BeNumerically("<=", 2100)))
+				years = append(years, date.Year())
+			}
+			Expect(slices.Max(years)).To(BeNumerically(">", 1300))
+		})
+	})
+
+	Context("When generating items", func() {
+		spec := examplev1.GenerateSpec{
+			Name:  "users",
+			Count: 3,
+			Seed:  7,
+			Fields: map[string]string{
+				"name":   "{{name}}",
+				"age":    "{{int 18 90}}",
+				"email":  "user-{{index}}@example.com",
+				"since":  "{{date 2024-01-01 2024-12-31}}",
+				"teamId": "{{ref teams}}",
+			},
+		}
+		ids := map[string][]any{"teams": {1, 2}}
+
+		It("should number items and render every field", func() {
+			items, err := Items(spec, "id", ids)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(items).To(HaveLen(3))
+			for i, item := range items {
+				Expect(item).To(HaveKeyWithValue("id", i+1))
+				Expect(item).To(HaveKeyWithValue("email", fmt.Sprintf("user-%d@example.com", i+1)))
+				Expect(item["age"]).To(And(BeNumerically(">=", 18), BeNumerically("<=", 90)))
+				Expect(item["teamId"]).To(BeElementOf(1, 2))
+				since, err := time.Parse(time.DateOnly, item["since"].(string))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(since.Year()).To(Equal(2024))
+			}
+		})
+
+		It("should be deterministic for a seed", func() {
+			first, err := Items(spec, "id", ids)
+			Expect(err).NotTo(HaveOccurred())
+			second, err := Items(spec, "id", ids)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(second).To(Equal(first))
+
+			reseeded := spec
+			reseeded.Seed = 8
+			third, err := Items(reseeded, "id", ids)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(third).NotTo(Equal(first))
+		})
+
+		It("should keep the id field from being overwritten", func() {
+			withID := spec
+			withID.Fields = map[string]string{"id": "{{word}}", "word": "{{word}}"}
+			items, err := Items(withID, "id", nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(items[2]).To(HaveKeyWithValue("id", 3))
+		})
+
+		It("should reject references to collections without items", func() {
+			_, err := Items(spec, "id", map[string][]any{"teams": {}})
+			Expect(err).To(MatchError(`field "teamId": collection "teams" has no items to reference`))
+		})
+
+		It("should report invalid fields", func() {
+			invalid := spec
+			invalid.Fields = map[string]string{"age": "{{int 0 1e19}}"}
+			_, err := Items(invalid, "id", nil)
+			Expect(err).To(MatchError(ContainSubstring(`field "age": {{int 0 1e19}}: maximum "1e19" is outside the int64 range`)))
+		})
+	})
+})
@@ -0,0 +1,145 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/yaml"
+)
+
+// NamingPolicyKey is the ConfigMap key holding the naming policy.
+const NamingPolicyKey = "policy.yaml"
+
+// defaultNamingPolicy applies when the operator is given no policy.
+var defaultNamingPolicy = &NamingPolicy{
+	NamingRules: NamingRules{Pattern: "^app-"},
+	source:      "the default naming policy",
+	patterns:    map[string]*regexp.Regexp{"^app-": regexp.MustCompile("^app-")},
+}
+
+// NamingRules restrict the names of new JsonServers.
+type NamingRules struct {
+	// Pattern is a regular expression every name must match.
+	Pattern string `json:"pattern,omitempty"`
+
+	// Reserved lists names that cannot be used.
+	Reserved []string `json:"reserved,omitempty"`
+}
+
+// NamingPolicy holds the naming rules of all namespaces and overrides for
+// single namespaces. An override replaces the pattern when it sets one and
+// reserves its names in addition to the shared ones.
+type NamingPolicy struct {
+	NamingRules
+
+	// Namespaces maps a namespace to its override.
+	Namespaces map[string]NamingRules `json:"namespaces,omitempty"`
+
+	// source names where the policy was loaded from in error messages.
+	source   string
+	patterns map[string]*regexp.Regexp
+}
+
+// ParseNamingPolicy parses a naming policy in YAML or JSON. source names
+// where it was loaded from, e.g. a file or ConfigMap, and is used in error
+// messages.
+func ParseNamingPolicy(data []byte, source string) (*NamingPolicy, error) {
+	p := &NamingPolicy{source: source, patterns: map[string]*regexp.Regexp{}}
+	if err := yaml.UnmarshalStrict(data, p); err != nil {
+		return nil, fmt.Errorf("naming policy in %s is invalid: %w", source, err)
+	}
+
+	compile := func(pattern string, fldPath *field.Path) error {
+		if pattern == "" {
+			return nil
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("naming policy in %s is invalid: %s: %w", source, fldPath, err)
+		}
+		p.patterns[pattern] = re
+		return nil
+	}
+	if err := compile(p.Pattern, field.NewPath("pattern")); err != nil {
+		return nil, err
+	}
+	for namespace, rules := range p.Namespaces {
+		if err := compile(rules.Pattern, field.NewPath("namespaces").Key(namespace).Child("pattern")); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// validate checks a name against the rules of its namespace. Errors name
+// the rule that failed and where the policy was loaded from.
+func (p *NamingPolicy) validate(namespace, name string, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	pattern, patternScope := p.Pattern, ""
+	override, overridden := p.Namespaces[namespace]
+	if overridden && override.Pattern != "" {
+		pattern, patternScope = override.Pattern, " for namespace "+namespace
+	}
+	if pattern != "" && !p.patterns[pattern].MatchString(name) {
+		errs = append(errs, field.Invalid(fldPath, name,
+			fmt.Sprintf("does not match the pattern %s%s of %s", pattern, patternScope, p.source)))
+	}
+
+	switch {
+	case slices.Contains(p.Reserved, name):
+		errs = append(errs, field.Forbidden(fldPath,
+			fmt.Sprintf("%s is reserved by %s", name, p.source)))
+	case overridden && slices.Contains(override.Reserved, name):
+		errs = append(errs, field.Forbidden(fldPath,
+			fmt.Sprintf("%s is reserved for namespace %s by %s", name, namespace, p.source)))
+	}
+	return errs
+}
+
+// namingPolicy returns the policy names are checked against: the one in
+// NamingPolicyConfigMap while it exists, else NamingPolicy, else the
+// default policy.
+func (v *JsonServerCustomValidator) namingPolicy(ctx context.Context) (*NamingPolicy, error) {
+	if v.NamingPolicyConfigMap != nil && v.Reader != nil {
+		cm := &corev1.ConfigMap{}
+		err := v.Reader.Get(ctx, *v.NamingPolicyConfigMap, cm)
+		switch {
+		case err == nil:
+			source := "ConfigMap " + v.NamingPolicyConfigMap.String()
+			data, ok := cm.Data[NamingPolicyKey]
+			if !ok {
+				return nil, fmt.Errorf("naming policy in %s is invalid: no %s key", source, NamingPolicyKey)
+			}
+			return ParseNamingPolicy([]byte(data), source)
+		case !apierrors.IsNotFound(err):
+			return nil, fmt.Errorf("cannot read the naming policy: %w", err)
+		}
+	}
+
+	if v.NamingPolicy != nil {
+		return v.NamingPolicy, nil
+	}
+	return defaultNamingPolicy, nil
+}
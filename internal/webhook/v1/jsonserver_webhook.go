@@ -17,34 +17,96 @@ limitations under the License.
 package v1
 
 import (
+	"cmp"
+	"compress/gzip"
 	"context"
 	"encoding/json"
 	"fmt"
+	"maps"
+	"regexp"
+	"slices"
 	"strings"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/kube-openapi/pkg/validation/spec"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	examplev1 "github.com/BlueTurtle-bytes/json-server/api/v1"
+	"github.com/BlueTurtle-bytes/json-server/internal/dataformat"
+	"github.com/BlueTurtle-bytes/json-server/internal/generate"
 )
 
 // nolint:unused
 // log is for logging in this package.
 var jsonserverlog = logf.Log.WithName("jsonserver-resource")
 
+// jsonServerGroupKind identifies JsonServer in field validation errors.
+var jsonServerGroupKind = examplev1.GroupVersion.WithKind("JsonServer").GroupKind()
+
+// Options configures the JsonServer webhooks.
+type Options struct {
+	// NamingPolicy restricts the names of new JsonServers. The default
+	// policy, which requires the app- prefix, is used when nil.
+	NamingPolicy *NamingPolicy
+
+	// NamingPolicyConfigMap names a ConfigMap whose policy.yaml key holds
+	// the naming policy. It takes precedence over NamingPolicy while it
+	// exists and is read on every create, so changes apply without a restart.
+	NamingPolicyConfigMap *types.NamespacedName
+}
+
+// SetupJsonServerConversionWithManager registers the conversion webhook for
+// JsonServer on /convert in the manager. JsonServers are stored as v2, so it
+// is needed to read them through v1, and is set up even when the admission
+// webhooks are disabled.
+func SetupJsonServerConversionWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr, &examplev1.JsonServer{}).Complete()
+}
+
 // SetupJsonServerWebhookWithManager registers the webhook for JsonServer in the manager.
-func SetupJsonServerWebhookWithManager(mgr ctrl.Manager) error {
+func SetupJsonServerWebhookWithManager(mgr ctrl.Manager, opts Options) error {
 	return ctrl.NewWebhookManagedBy(mgr, &examplev1.JsonServer{}).
-		WithValidator(&JsonServerCustomValidator{}).
+		WithDefaulter(&JsonServerCustomDefaulter{}).
+		WithValidator(&JsonServerCustomValidator{
+			Reader:                mgr.GetAPIReader(),
+			NamingPolicy:          opts.NamingPolicy,
+			NamingPolicyConfigMap: opts.NamingPolicyConfigMap,
+		}).
 		Complete()
 }
 
 // TODO(user): EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
 
-// TODO(user): change verbs to "verbs=create;update;delete" if you want to enable deletion validation.
+// +kubebuilder:webhook:path=/mutate-example-com-v1-jsonserver,mutating=true,failurePolicy=fail,sideEffects=None,groups=example.com,resources=jsonservers,verbs=create;update,versions=v1,name=mjsonserver-v1.kb.io,admissionReviewVersions=v1
+
+// JsonServerCustomDefaulter struct is responsible for setting default values on the custom resource of the
+// Kind JsonServer when those are created or updated.
+//
+// NOTE: The +kubebuilder:object:generate=false marker prevents controller-gen from generating DeepCopy methods,
+// as it is used only for temporary operations and does not need to be deeply copied.
+type JsonServerCustomDefaulter struct{}
+
+// Default implements webhook.CustomDefaulter so a webhook will be registered for the Kind JsonServer.
+// It stores the values the controller would otherwise assume, so the object
+// shows its effective spec.
+func (d *JsonServerCustomDefaulter) Default(_ context.Context, obj *examplev1.JsonServer) error {
+	jsonserverlog.Info("Defaulting for JsonServer", "name", obj.GetName())
+
+	examplev1.SetDefaults(obj)
+	return nil
+}
+
 // NOTE: If you want to customise the 'path', use the flags '--defaulting-path' or '--validation-path'.
-// +kubebuilder:webhook:path=/validate-example-com-v1-jsonserver,mutating=false,failurePolicy=fail,sideEffects=None,groups=example.com,resources=jsonservers,verbs=create;update,versions=v1,name=vjsonserver-v1.kb.io,admissionReviewVersions=v1
+// +kubebuilder:webhook:path=/validate-example-com-v1-jsonserver,mutating=false,failurePolicy=fail,sideEffects=None,groups=example.com,resources=jsonservers,verbs=create;update;delete,versions=v1,name=vjsonserver-v1.kb.io,admissionReviewVersions=v1
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get
 
 // JsonServerCustomValidator struct is responsible for validating the JsonServer resource
 // when it is created, updated, or deleted.
@@ -52,22 +114,44 @@ func SetupJsonServerWebhookWithManager(mgr ctrl.Manager) error {
 // NOTE: The +kubebuilder:object:generate=false marker prevents controller-gen from generating DeepCopy methods,
 // as this struct is used only for temporary operations and does not need to be deeply copied.
 type JsonServerCustomValidator struct {
-	// TODO(user): Add more fields as needed for validation
+	// Reader reads the namespace of a deleted JsonServer for its deletion
+	// protection policy and the naming policy ConfigMap. Neither is read
+	// when nil.
+	Reader client.Reader
+
+	// NamingPolicy and NamingPolicyConfigMap are described in Options.
+	NamingPolicy          *NamingPolicy
+	NamingPolicyConfigMap *types.NamespacedName
 }
 
 // ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type JsonServer.
-func (v *JsonServerCustomValidator) ValidateCreate(_ context.Context, obj *examplev1.JsonServer) (admission.Warnings, error) {
+func (v *JsonServerCustomValidator) ValidateCreate(ctx context.Context, obj *examplev1.JsonServer) (admission.Warnings, error) {
 
-	if !strings.HasPrefix(obj.Name, "app-") {
-		return nil, fmt.Errorf("Error: metadata.name must start with app-")
+	policy, err := v.namingPolicy(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if errs := policy.validate(obj.Namespace, obj.Name, field.NewPath("metadata", "name")); len(errs) > 0 {
+		return nil, apierrors.NewInvalid(jsonServerGroupKind, obj.Name, errs)
 	}
 
-	var js any
-	if err := json.Unmarshal([]byte(obj.Spec.JsonConfig), &js); err != nil {
-		return nil, fmt.Errorf("Error: spec.jsonConfig is not a valid json object")
+	// Referenced and generated data is validated by the controller once it
+	// is read, and collections alone need no jsonConfig.
+	if obj.Spec.DataFrom == nil && obj.Spec.OpenAPI == nil &&
+		(obj.Spec.JsonConfig != "" || (len(obj.Spec.Collections) == 0 && len(obj.Spec.Generate) == 0)) {
+		decoded, err := decodeConfig(obj)
+		if err != nil {
+			return nil, fmt.Errorf("Error: spec.jsonConfig is not valid %s: %w",
+				cmp.Or(obj.Spec.DataFormat, examplev1.DataFormatJSON), err)
+		}
+		obj = decoded
+		errs := examplev1.ValidateDB([]byte(obj.Spec.JsonConfig), obj.Spec.Server, field.NewPath("spec", "jsonConfig"))
+		if len(errs) > 0 {
+			return nil, apierrors.NewInvalid(jsonServerGroupKind, obj.Name, errs)
+		}
 	}
 
-	return nil, nil
+	return specWarnings(obj), validateSpec(obj)
 }
 
 // ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type JsonServer.
@@ -78,17 +162,572 @@ func (v *JsonServerCustomValidator) ValidateUpdate(_ context.Context, oldObj, ne
 		return nil, fmt.Errorf("metadata.name is immutable")
 	}
 
+	// Metadata-only updates, such as adding or removing the finalizer, and
+	// updates of an object being deleted skip the spec checks, so an object
+	// that no longer passes them can still be deleted. The promote
+	// annotation is usually added on its own, so it is checked when it
+	// changes.
+	if !newObj.DeletionTimestamp.IsZero() {
+		return nil, nil
+	}
+	if !specChanged(oldObj, newObj) {
+		promote := examplev1.PromoteSnapshotAnnotation
+		if oldObj.Annotations[promote] == newObj.Annotations[promote] {
+			return nil, nil
+		}
+		if errs := validatePromotion(newObj); len(errs) > 0 {
+			return nil, apierrors.NewInvalid(jsonServerGroupKind, newObj.Name, errs)
+		}
+		return nil, nil
+	}
+
 	// Allow invalid JSON updates
 	// Controller will detect and update status
 
-	return nil, nil
+	if err := validateStorageUpdate(oldObj, newObj); err != nil {
+		return nil, err
+	}
+	if decoded, err := decodeConfig(newObj); err == nil {
+		newObj = decoded
+	}
+
+	return specWarnings(newObj), validateSpec(newObj)
+}
+
+// specChanged reports whether the update changes the spec. The new object
+// has been defaulted, so the old spec is compared with its defaults too.
+func specChanged(oldObj, newObj *examplev1.JsonServer) bool {
+	oldObj = oldObj.DeepCopy()
+	examplev1.SetDefaults(oldObj)
+	return !equality.Semantic.DeepEqual(oldObj.Spec, newObj.Spec)
+}
+
+// decodeConfig returns a copy of obj with jsonConfig decoded from
+// spec.dataFormat to the JSON the controller serves, so the checks below
+// see the same data.
+func decodeConfig(obj *examplev1.JsonServer) (*examplev1.JsonServer, error) {
+	decoded, err := dataformat.Decode([]byte(obj.Spec.JsonConfig), obj.Spec.DataFormat)
+	if err != nil {
+		return nil, err
+	}
+	obj = obj.DeepCopy()
+	obj.Spec.JsonConfig = string(decoded)
+	return obj, nil
+}
+
+// validateSpec checks the structured spec fields that the controller renders
+// without further checks. jsonConfig is validated separately.
+func validateSpec(obj *examplev1.JsonServer) error {
+	specPath := field.NewPath("spec")
+
+	var errs field.ErrorList
+	errs = append(errs, validateDataSource(obj, specPath)...)
+	errs = append(errs, validatePromotion(obj)...)
+	errs = append(errs, validateCollections(obj, specPath)...)
+	errs = append(errs, validateSchemas(obj, specPath.Child("schemas"))...)
+	errs = append(errs, validateConfigSize(obj.Spec.JsonConfig, specPath.Child("jsonConfig"))...)
+	errs = append(errs, validateServer(obj.Spec.Server, specPath.Child("server"))...)
+	errs = append(errs, validateStorage(obj.Spec.Storage, specPath.Child("storage"))...)
+	errs = append(errs, validateConsistency(obj, specPath)...)
+	errs = append(errs, validateService(obj.Spec.Service, specPath.Child("service"))...)
+	errs = append(errs, validateIngress(obj.Spec.Ingress, specPath.Child("ingress"))...)
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(jsonServerGroupKind, obj.Name, errs)
+}
+
+// validateDataSource allows at most one of jsonConfig, dataFrom and openAPI,
+// and requires one of them unless collections are set. dataFrom needs exactly
+// one reference.
+func validateDataSource(obj *examplev1.JsonServer, specPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	src := obj.Spec.DataFrom
+
+	var sources []string
+	if obj.Spec.JsonConfig != "" {
+		sources = append(sources, "jsonConfig")
+	}
+	if src != nil {
+		sources = append(sources, "dataFrom")
+	}
+	if obj.Spec.OpenAPI != nil {
+		sources = append(sources, "openAPI")
+	}
+	switch {
+	case len(sources) == 0 && len(obj.Spec.Collections) == 0 && len(obj.Spec.Generate) == 0:
+		errs = append(errs, field.Required(specPath.Child("jsonConfig"),
+			"one of jsonConfig, dataFrom, openAPI, collections and generate must be set"))
+	case len(sources) > 1:
+		errs = append(errs, field.Forbidden(specPath.Child(sources[1]),
+			"at most one of jsonConfig, dataFrom and openAPI may be set"))
+	}
+
+	if openAPI := obj.Spec.OpenAPI; openAPI != nil {
+		ref := openAPI.ConfigMapKeyRef
+		errs = append(errs, validateKeyRef(ref.Name, ref.Key, specPath.Child("openAPI", "configMapKeyRef"))...)
+		if format := obj.Spec.DataFormat; format != "" && format != examplev1.DataFormatJSON {
+			errs = append(errs, field.Invalid(specPath.Child("dataFormat"), format,
+				"must be json when openAPI is set, which always generates JSON"))
+		}
+	}
+	if src == nil {
+		return errs
+	}
+
+	fldPath := specPath.Child("dataFrom")
+	if (src.ConfigMapKeyRef == nil) == (src.SecretKeyRef == nil) {
+		errs = append(errs, field.Invalid(fldPath, "",
+			"exactly one of configMapKeyRef and secretKeyRef must be set"))
+	}
+	if ref := src.ConfigMapKeyRef; ref != nil {
+		errs = append(errs, validateKeyRef(ref.Name, ref.Key, fldPath.Child("configMapKeyRef"))...)
+	}
+	if ref := src.SecretKeyRef; ref != nil {
+		errs = append(errs, validateKeyRef(ref.Name, ref.Key, fldPath.Child("secretKeyRef"))...)
+		// Snapshots are ConfigMaps, which must not hold Secret data.
+		if obj.Spec.DeletionPolicy == examplev1.DeletionPolicySnapshot {
+			errs = append(errs, field.Invalid(specPath.Child("deletionPolicy"), obj.Spec.DeletionPolicy,
+				"data from a Secret is not copied into a snapshot ConfigMap"))
+		}
+	}
+
+	return errs
+}
+
+// validatePromotion denies the promote annotation while the spec has data
+// that the promoted spec.jsonConfig would clash with: dataFrom and openAPI
+// exclude jsonConfig, and the snapshot of a composed instance already holds
+// its collections.
+func validatePromotion(obj *examplev1.JsonServer) field.ErrorList {
+	var errs field.ErrorList
+	if _, ok := obj.Annotations[examplev1.PromoteSnapshotAnnotation]; !ok {
+		return errs
+	}
+
+	fldPath := field.NewPath("metadata", "annotations").Key(examplev1.PromoteSnapshotAnnotation)
+	if obj.Spec.DataFrom != nil {
+		errs = append(errs, field.Forbidden(fldPath, "snapshots cannot be promoted while spec.dataFrom is set"))
+	}
+	if obj.Spec.OpenAPI != nil {
+		errs = append(errs, field.Forbidden(fldPath, "snapshots cannot be promoted while spec.openAPI is set"))
+	}
+	if len(obj.Spec.Collections) > 0 || len(obj.Spec.Generate) > 0 {
+		errs = append(errs, field.Forbidden(fldPath,
+			"snapshots cannot be promoted while spec.collections or spec.generate is set"))
+	}
+	return errs
+}
+
+// validateCollections requires unique names across collections and
+// generate that are not also keys of an inline jsonConfig, exactly one
+// source per collection and valid generate templates. Collections from
+// ConfigMaps are checked by the controller once read.
+func validateCollections(obj *examplev1.JsonServer, specPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if len(obj.Spec.Collections) == 0 && len(obj.Spec.Generate) == 0 {
+		return errs
+	}
+
+	// An unparsable jsonConfig is reported on its own.
+	var inline map[string]json.RawMessage
+	_ = json.Unmarshal([]byte(obj.Spec.JsonConfig), &inline)
+
+	names := map[string]bool{}
+	validateName := func(name string, fldPath *field.Path) {
+		switch {
+		case name == "":
+			errs = append(errs, field.Required(fldPath, ""))
+		case !collectionNameRegexp.MatchString(name):
+			errs = append(errs, field.Invalid(fldPath, name,
+				"must consist of letters, digits, '-' and '_' and start with a letter or digit"))
+		case names[name]:
+			errs = append(errs, field.Duplicate(fldPath, name))
+		default:
+			if _, ok := inline[name]; ok {
+				errs = append(errs, field.Duplicate(fldPath, name))
+			}
+		}
+		names[name] = true
+	}
+
+	for i, c := range obj.Spec.Collections {
+		idxPath := specPath.Child("collections").Index(i)
+		validateName(c.Name, idxPath.Child("name"))
+
+		if (c.Inline == "") == (c.ConfigMapKeyRef == nil) {
+			errs = append(errs, field.Invalid(idxPath, c.Name,
+				"exactly one of inline and configMapKeyRef must be set"))
+		}
+		if c.Inline != "" {
+			data, _, err := dataformat.DecodeCollection([]byte(c.Inline), c.Format)
+			switch {
+			case err != nil:
+				errs = append(errs, field.Invalid(idxPath.Child("inline"),
+					fmt.Sprintf("<invalid %s>", cmp.Or(c.Format, examplev1.CollectionFormatJSON)), err.Error()))
+			case !dataformat.IsCollection(data):
+				errs = append(errs, field.Invalid(idxPath.Child("inline"), "<invalid json>",
+					"must be a json array or object"))
+			}
+		}
+		if ref := c.ConfigMapKeyRef; ref != nil {
+			errs = append(errs, validateKeyRef(ref.Name, ref.Key, idxPath.Child("configMapKeyRef"))...)
+		}
+	}
+
+	idField := examplev1.DefaultIDField
+	if obj.Spec.Server != nil && obj.Spec.Server.IDField != "" {
+		idField = obj.Spec.Server.IDField
+	}
+	for i, g := range obj.Spec.Generate {
+		idxPath := specPath.Child("generate").Index(i)
+		validateName(g.Name, idxPath.Child("name"))
+
+		for _, name := range slices.Sorted(maps.Keys(g.Fields)) {
+			fldPath := idxPath.Child("fields").Key(name)
+			if name == idField {
+				errs = append(errs, field.Forbidden(fldPath, "ids of generated items are numbered from 1"))
+				continue
+			}
+			if _, err := generate.Parse(g.Fields[name]); err != nil {
+				errs = append(errs, field.Invalid(fldPath, g.Fields[name], err.Error()))
+			}
+		}
+	}
+
+	return errs
+}
+
+// validateSchemas requires exactly one source per schema and a parsable
+// inline schema. With Deny enforcement jsonConfig must match the inline
+// schemas; schemas and data from ConfigMaps are checked by the controller.
+func validateSchemas(obj *examplev1.JsonServer, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	for _, name := range slices.Sorted(maps.Keys(obj.Spec.Schemas)) {
+		src := obj.Spec.Schemas[name]
+		keyPath := fldPath.Key(name)
+
+		if (src.Inline == "") == (src.ConfigMapKeyRef == nil) {
+			errs = append(errs, field.Invalid(keyPath, name,
+				"exactly one of inline and configMapKeyRef must be set"))
+		}
+		if src.Inline != "" {
+			if _, err := examplev1.ParseSchema(src.Inline); err != nil {
+				errs = append(errs, field.Invalid(keyPath.Child("inline"), "<invalid schema>",
+					"must be a JSON Schema: "+err.Error()))
+			}
+		}
+		if ref := src.ConfigMapKeyRef; ref != nil {
+			errs = append(errs, validateKeyRef(ref.Name, ref.Key, keyPath.Child("configMapKeyRef"))...)
+		}
+	}
+
+	if obj.Spec.SchemaEnforcement != examplev1.SchemaEnforcementWarn {
+		errs = append(errs, schemaMismatches(obj)...)
+	}
+	return errs
+}
+
+// schemaMismatches matches jsonConfig against the inline schemas. Invalid
+// schemas are reported by validateSchemas and skipped here.
+func schemaMismatches(obj *examplev1.JsonServer) field.ErrorList {
+	schemas := map[string]*spec.Schema{}
+	for name, src := range obj.Spec.Schemas {
+		if schema, err := examplev1.ParseSchema(src.Inline); src.Inline != "" && err == nil {
+			schemas[name] = schema
+		}
+	}
+	return examplev1.ValidateSchemas([]byte(obj.Spec.JsonConfig), schemas, field.NewPath("spec", "jsonConfig"))
+}
+
+func validateKeyRef(name, key string, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if name == "" {
+		errs = append(errs, field.Required(fldPath.Child("name"), ""))
+	}
+	for _, msg := range validation.IsConfigMapKey(key) {
+		errs = append(errs, field.Invalid(fldPath.Child("key"), key, msg))
+	}
+	return errs
+}
+
+// validateConfigSize rejects data that does not fit into the ConfigMap even
+// after the controller compresses it, instead of failing at reconcile time.
+func validateConfigSize(config string, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if len(config) <= examplev1.CompressThresholdBytes {
+		return errs
+	}
+
+	var compressed byteCounter
+	zw, _ := gzip.NewWriterLevel(&compressed, gzip.BestCompression)
+	_, _ = zw.Write([]byte(config))
+	_ = zw.Close()
+
+	if int(compressed) > examplev1.MaxConfigMapBytes {
+		errs = append(errs, field.Invalid(fldPath, fmt.Sprintf("<%d bytes>", len(config)),
+			fmt.Sprintf("is %d bytes gzip-compressed, more than the %d bytes a ConfigMap can hold; "+
+				"reduce the data set", int(compressed), examplev1.MaxConfigMapBytes)))
+	}
+
+	return errs
+}
+
+// byteCounter is an io.Writer that only counts what is written to it.
+type byteCounter int
+
+func (c *byteCounter) Write(p []byte) (int, error) {
+	*c += byteCounter(len(p))
+	return len(p), nil
+}
+
+// reservedFilePrefix marks ConfigMap files generated by the controller.
+const reservedFilePrefix = "json-server-"
+
+var (
+	// middlewareNameRegexp matches file names that are also valid ConfigMap keys.
+	middlewareNameRegexp = regexp.MustCompile(`^[A-Za-z0-9][-._A-Za-z0-9]*\.js$`)
+	// collectionNameRegexp matches collection names usable as a URL path segment.
+	collectionNameRegexp = regexp.MustCompile(`^[A-Za-z0-9][-_A-Za-z0-9]*$`)
+	// propertyNameRegexp matches plain JavaScript property names.
+	propertyNameRegexp = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+)
+
+func validateServer(server *examplev1.ServerSpec, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if server == nil {
+		return errs
+	}
+
+	for _, from := range slices.Sorted(maps.Keys(server.Routes)) {
+		to := server.Routes[from]
+		routePath := fldPath.Child("routes").Key(from)
+		if !strings.HasPrefix(from, "/") {
+			errs = append(errs, field.Invalid(routePath, from, "route must start with /"))
+		}
+		if !strings.HasPrefix(to, "/") {
+			errs = append(errs, field.Invalid(routePath, to, "rewrite target must start with /"))
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(server.Middlewares)) {
+		source := server.Middlewares[name]
+		mwPath := fldPath.Child("middlewares").Key(name)
+		if !middlewareNameRegexp.MatchString(name) {
+			errs = append(errs, field.Invalid(mwPath, name,
+				"must be a file name ending in .js made of alphanumerics, '-', '_' or '.'"))
+		}
+		if strings.HasPrefix(name, reservedFilePrefix) {
+			errs = append(errs, field.Invalid(mwPath, name,
+				fmt.Sprintf("the %s prefix is reserved for files generated by the operator", reservedFilePrefix)))
+		}
+		if strings.TrimSpace(source) == "" {
+			errs = append(errs, field.Required(mwPath, "middleware source must not be empty"))
+		}
+	}
+
+	if server.DelayMs != nil && *server.DelayMs < 0 {
+		errs = append(errs, field.Invalid(fldPath.Child("delayMs"), *server.DelayMs, "must not be negative"))
+	}
+
+	if server.IDField != "" && !propertyNameRegexp.MatchString(server.IDField) {
+		errs = append(errs, field.Invalid(fldPath.Child("idField"), server.IDField,
+			"must be a plain property name"))
+	}
+	if server.ForeignKeySuffix != "" && !propertyNameRegexp.MatchString(server.ForeignKeySuffix) {
+		errs = append(errs, field.Invalid(fldPath.Child("foreignKeySuffix"), server.ForeignKeySuffix,
+			"must be a plain property name"))
+	}
+
+	return errs
+}
+
+func validateStorage(storage *examplev1.StorageSpec, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if storage == nil {
+		return errs
+	}
+
+	// A zero size is replaced by the CRD default before admission.
+	if storage.Size.Sign() < 0 {
+		errs = append(errs, field.Invalid(fldPath.Child("size"), storage.Size.String(), "must be positive"))
+	}
+
+	return errs
+}
+
+func validateService(service *examplev1.ServiceSpec, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if service == nil {
+		return errs
+	}
+
+	if service.NodePort != 0 && (service.Type == "" || service.Type == corev1.ServiceTypeClusterIP) {
+		errs = append(errs, field.Forbidden(fldPath.Child("nodePort"),
+			fmt.Sprintf("may only be set when type is %s or %s",
+				corev1.ServiceTypeNodePort, corev1.ServiceTypeLoadBalancer)))
+	}
+
+	return errs
+}
+
+func validateIngress(ingress *examplev1.IngressSpec, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if ingress == nil {
+		return errs
+	}
+
+	for _, msg := range validation.IsDNS1123Subdomain(ingress.Host) {
+		errs = append(errs, field.Invalid(fldPath.Child("host"), ingress.Host, msg))
+	}
+	if ingress.Path != "" && !strings.HasPrefix(ingress.Path, "/") {
+		errs = append(errs, field.Invalid(fldPath.Child("path"), ingress.Path, "must start with /"))
+	}
+
+	if ingress.Gateway != nil {
+		if ingress.TLSSecretName != "" {
+			errs = append(errs, field.Forbidden(fldPath.Child("tlsSecretName"),
+				"TLS is terminated by the Gateway listener; set gateway.https instead"))
+		}
+		if ingress.ClassName != nil {
+			errs = append(errs, field.Forbidden(fldPath.Child("className"),
+				"only applies to an Ingress, not to a Gateway"))
+		}
+	}
+
+	return errs
+}
+
+// validateConsistency rejects replica counts the data layout cannot serve.
+func validateConsistency(obj *examplev1.JsonServer, specPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	// Only the SingleWriter mode mounts the ReadWriteOnce claim in one pod.
+	if obj.Spec.Storage != nil && replicas(obj) > 1 && consistencyMode(obj) != examplev1.ConsistencySingleWriter {
+		errs = append(errs, field.Forbidden(specPath.Child("replicas"),
+			fmt.Sprintf("%d replicas cannot share the ReadWriteOnce volume of spec.storage; "+
+				"set spec.consistency.mode to %s or use 1 replica",
+				replicas(obj), examplev1.ConsistencySingleWriter)))
+	}
+
+	return errs
+}
+
+// specWarnings flags valid specs that are likely to surprise: scaled
+// replicas serving diverging or read-only data.
+func specWarnings(obj *examplev1.JsonServer) admission.Warnings {
+	var warnings admission.Warnings
+	if obj.Spec.SchemaEnforcement == examplev1.SchemaEnforcementWarn {
+		for _, err := range schemaMismatches(obj) {
+			warnings = append(warnings, err.Error())
+		}
+	}
+	for i, c := range obj.Spec.Collections {
+		if c.Inline == "" {
+			continue
+		}
+		// Skipped rows are reported in status too; invalid data is an error.
+		if _, rowErrs, err := dataformat.DecodeCollection([]byte(c.Inline), c.Format); err == nil && len(rowErrs) > 0 {
+			warnings = append(warnings, fmt.Sprintf(
+				"spec.collections[%d].inline: %d rows are skipped, the first at line %d: %s",
+				i, len(rowErrs), rowErrs[0].Line, rowErrs[0].Message))
+		}
+	}
+	if replicas(obj) <= 1 {
+		return warnings
+	}
+
+	switch consistencyMode(obj) {
+	case examplev1.ConsistencyIndependent:
+		warnings = append(warnings, fmt.Sprintf(
+			"spec.replicas is %d and spec.consistency.mode is %s: each replica keeps its own data, "+
+				"so writes are only visible on the replica that received them",
+			replicas(obj), examplev1.ConsistencyIndependent))
+	case examplev1.ConsistencyReadOnlyWhenScaled:
+		warnings = append(warnings, fmt.Sprintf(
+			"spec.replicas is %d: json-server runs with --read-only and rejects writes",
+			replicas(obj)))
+	}
+
+	return warnings
+}
+
+func replicas(obj *examplev1.JsonServer) int32 {
+	if obj.Spec.Replicas != nil {
+		return *obj.Spec.Replicas
+	}
+	return 1
+}
+
+func consistencyMode(obj *examplev1.JsonServer) examplev1.ConsistencyMode {
+	if obj.Spec.Consistency == nil || obj.Spec.Consistency.Mode == "" {
+		return examplev1.ConsistencyIndependent
+	}
+	return obj.Spec.Consistency.Mode
+}
+
+// validateStorageUpdate rejects changes the existing PersistentVolumeClaim
+// cannot follow.
+func validateStorageUpdate(oldObj, newObj *examplev1.JsonServer) error {
+	oldStorage, newStorage := oldObj.Spec.Storage, newObj.Spec.Storage
+	if oldStorage == nil || newStorage == nil {
+		return nil
+	}
+
+	fldPath := field.NewPath("spec", "storage")
+	var errs field.ErrorList
+
+	if !equality.Semantic.DeepEqual(oldStorage.StorageClassName, newStorage.StorageClassName) {
+		errs = append(errs, field.Forbidden(fldPath.Child("storageClassName"), "storageClassName is immutable"))
+	}
+	if newStorage.Size.Cmp(oldStorage.Size) < 0 {
+		errs = append(errs, field.Forbidden(fldPath.Child("size"),
+			fmt.Sprintf("size cannot shrink from %s", oldStorage.Size.String())))
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(jsonServerGroupKind, newObj.Name, errs)
 }
 
 // ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type JsonServer.
-func (v *JsonServerCustomValidator) ValidateDelete(_ context.Context, obj *examplev1.JsonServer) (admission.Warnings, error) {
+// It rejects deletes of protected JsonServers.
+func (v *JsonServerCustomValidator) ValidateDelete(ctx context.Context, obj *examplev1.JsonServer) (admission.Warnings, error) {
 	jsonserverlog.Info("Validation for JsonServer upon deletion", "name", obj.GetName())
 
-	// TODO(user): fill in your validation logic upon object deletion.
+	protectedBy, err := v.deletionProtection(ctx, obj)
+	if err != nil || protectedBy == "" {
+		return nil, err
+	}
+	return nil, apierrors.NewForbidden(examplev1.GroupVersion.WithResource("jsonservers").GroupResource(),
+		obj.Name, fmt.Errorf("deletion protection is enabled by %s", protectedBy))
+}
 
-	return nil, nil
+// deletionProtection describes what protects the JsonServer from deletion
+// and how to lift it, or returns "" when it may be deleted. Everything in a
+// terminating namespace may be deleted, so protection never blocks the
+// deletion of its namespace.
+func (v *JsonServerCustomValidator) deletionProtection(ctx context.Context, obj *examplev1.JsonServer) (string, error) {
+	ns := &corev1.Namespace{}
+	if v.Reader != nil {
+		if err := v.Reader.Get(ctx, client.ObjectKey{Name: obj.Namespace}, ns); client.IgnoreNotFound(err) != nil {
+			return "", fmt.Errorf("cannot read the deletion protection policy of namespace %s: %w", obj.Namespace, err)
+		}
+	}
+
+	switch {
+	case ns.Status.Phase == corev1.NamespaceTerminating:
+		return "", nil
+	case obj.Spec.DeletionProtection:
+		return "spec.deletionProtection; set it to false to delete the JsonServer", nil
+	case obj.Annotations[examplev1.ProtectedAnnotation] == "true":
+		return fmt.Sprintf("the %s annotation; remove it to delete the JsonServer",
+			examplev1.ProtectedAnnotation), nil
+	case ns.Labels[examplev1.DeletionProtectionLabel] == examplev1.DeletionProtectionRequired:
+		return fmt.Sprintf("namespace %s, labelled %s=%s; remove the label to delete JsonServers in it",
+			obj.Namespace, examplev1.DeletionProtectionLabel, examplev1.DeletionProtectionRequired), nil
+	}
+	return "", nil
 }
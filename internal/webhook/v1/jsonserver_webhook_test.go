@@ -2,13 +2,22 @@ package v1
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"strings"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	examplev1 "github.com/BlueTurtle-bytes/json-server/api/v1"
+	"github.com/BlueTurtle-bytes/json-server/internal/dataformat"
 )
 
 var _ = Describe("JsonServer Webhook", func() {
@@ -34,7 +43,8 @@ var _ = Describe("JsonServer Webhook", func() {
 			}
 
 			_, err := validator.ValidateCreate(ctx, obj)
-			Expect(err).To(HaveOccurred())
+			Expect(err).To(MatchError(ContainSubstring(
+				`metadata.name: Invalid value: "invalid-name": does not match the pattern ^app- of the default naming policy`)))
 		})
 
 		It("should deny creation when jsonConfig is invalid JSON", func() {
@@ -68,6 +78,647 @@ var _ = Describe("JsonServer Webhook", func() {
 			_, err := validator.ValidateCreate(ctx, obj)
 			Expect(err).NotTo(HaveOccurred())
 		})
+
+		It("should allow valid server options", func() {
+			delay := int32(250)
+			obj := &examplev1.JsonServer{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "app-valid",
+				},
+				Spec: examplev1.JsonServerSpec{
+					JsonConfig: `{}`,
+					Server: &examplev1.ServerSpec{
+						Routes:           map[string]string{"/api/*": "/$1"},
+						Middlewares:      map[string]string{"auth.js": "module.exports = (req, res, next) => next()"},
+						DelayMs:          &delay,
+						ReadOnly:         true,
+						IDField:          "_id",
+						ForeignKeySuffix: "_id",
+					},
+				},
+			}
+
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should deny invalid server options with field paths", func() {
+			obj := &examplev1.JsonServer{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "app-valid",
+				},
+				Spec: examplev1.JsonServerSpec{
+					JsonConfig: `{}`,
+					Server: &examplev1.ServerSpec{
+						Routes:      map[string]string{"api/*": "/$1"},
+						Middlewares: map[string]string{"../auth.sh": "echo"},
+						IDField:     "my id",
+					},
+				},
+			}
+
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.server.routes[api/*]"))
+			Expect(err.Error()).To(ContainSubstring("spec.server.middlewares[../auth.sh]"))
+			Expect(err.Error()).To(ContainSubstring("spec.server.idField"))
+		})
+
+		It("should deny a node port on a ClusterIP Service", func() {
+			obj := &examplev1.JsonServer{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "app-valid",
+				},
+				Spec: examplev1.JsonServerSpec{
+					JsonConfig: `{}`,
+					Service:    &examplev1.ServiceSpec{NodePort: 30080},
+				},
+			}
+
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.service.nodePort")))
+
+			obj.Spec.Service.Type = corev1.ServiceTypeNodePort
+			_, err = validator.ValidateCreate(ctx, obj)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Context("Naming policy", func() {
+		const policy = `
+pattern: ^mock-
+reserved: [mock-admin]
+namespaces:
+  staging:
+    pattern: ^stg-[a-z]+$
+    reserved: [stg-shared]
+`
+		newNamed := func(namespace, name string) *examplev1.JsonServer {
+			return &examplev1.JsonServer{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+				Spec:       examplev1.JsonServerSpec{JsonConfig: `{}`},
+			}
+		}
+
+		It("should apply the rules of the namespace and name the failing one", func() {
+			var err error
+			validator.NamingPolicy, err = ParseNamingPolicy([]byte(policy), "file policy.yaml")
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = validator.ValidateCreate(ctx, newNamed("default", "mock-users"))
+			Expect(err).NotTo(HaveOccurred())
+			_, err = validator.ValidateCreate(ctx, newNamed("staging", "stg-users"))
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = validator.ValidateCreate(ctx, newNamed("default", "app-users"))
+			Expect(err).To(MatchError(ContainSubstring(
+				`"app-users": does not match the pattern ^mock- of file policy.yaml`)))
+			_, err = validator.ValidateCreate(ctx, newNamed("staging", "mock-users"))
+			Expect(err).To(MatchError(ContainSubstring(
+				"does not match the pattern ^stg-[a-z]+$ for namespace staging of file policy.yaml")))
+			_, err = validator.ValidateCreate(ctx, newNamed("default", "mock-admin"))
+			Expect(err).To(MatchError(ContainSubstring("mock-admin is reserved by file policy.yaml")))
+			_, err = validator.ValidateCreate(ctx, newNamed("staging", "stg-shared"))
+			Expect(err).To(MatchError(ContainSubstring(
+				"stg-shared is reserved for namespace staging by file policy.yaml")))
+		})
+
+		It("should reject invalid policies", func() {
+			_, err := ParseNamingPolicy([]byte("namespaces:\n  dev:\n    pattern: \"[\"\n"), "file policy.yaml")
+			Expect(err).To(MatchError(ContainSubstring("namespaces[dev].pattern")))
+			_, err = ParseNamingPolicy([]byte("prefix: app-"), "file policy.yaml")
+			Expect(err).To(MatchError(ContainSubstring(`unknown field "prefix"`)))
+		})
+
+		It("should read the policy from a ConfigMap while it exists", func() {
+			key := types.NamespacedName{Namespace: "json-server-system", Name: "naming-policy"}
+			cm := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+				Data:       map[string]string{NamingPolicyKey: policy},
+			}
+			validator.NamingPolicyConfigMap = &key
+			validator.Reader = fake.NewClientBuilder().WithObjects(cm).Build()
+
+			_, err := validator.ValidateCreate(ctx, newNamed("default", "app-users"))
+			Expect(err).To(MatchError(ContainSubstring(
+				"of ConfigMap json-server-system/naming-policy")))
+
+			validator.Reader = fake.NewClientBuilder().Build()
+			_, err = validator.ValidateCreate(ctx, newNamed("default", "app-users"))
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Context("Data source", func() {
+		newSourced := func(config string, src *examplev1.DataSource) *examplev1.JsonServer {
+			return &examplev1.JsonServer{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "app-sourced",
+				},
+				Spec: examplev1.JsonServerSpec{
+					JsonConfig: config,
+					DataFrom:   src,
+				},
+			}
+		}
+		configMapRef := &examplev1.DataSource{
+			ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "fixtures"},
+				Key:                  "db.json",
+			},
+		}
+
+		It("should allow data from a ConfigMap key", func() {
+			_, err := validator.ValidateCreate(ctx, newSourced("", configMapRef))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should require exactly one source", func() {
+			_, err := validator.ValidateCreate(ctx, newSourced(`{}`, configMapRef))
+			Expect(err).To(MatchError(ContainSubstring("spec.dataFrom")))
+
+			_, err = validator.ValidateCreate(ctx, newSourced("", nil))
+			Expect(err).To(HaveOccurred())
+
+			both := configMapRef.DeepCopy()
+			both.SecretKeyRef = &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "fixtures"},
+				Key:                  "db.json",
+			}
+			_, err = validator.ValidateCreate(ctx, newSourced("", both))
+			Expect(err).To(MatchError(ContainSubstring("exactly one of configMapKeyRef and secretKeyRef")))
+		})
+
+		It("should reject snapshots on deletion of data from a Secret", func() {
+			obj := newSourced("", &examplev1.DataSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "fixtures"},
+					Key:                  "db.json",
+				},
+			})
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).NotTo(HaveOccurred())
+
+			obj.Spec.DeletionPolicy = examplev1.DeletionPolicySnapshot
+			_, err = validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.deletionPolicy")))
+		})
+
+		It("should allow generating data from an OpenAPI document instead", func() {
+			obj := newSourced("", nil)
+			obj.Spec.OpenAPI = &examplev1.OpenAPISource{
+				ConfigMapKeyRef: corev1.ConfigMapKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "contracts"},
+					Key:                  "openapi.yaml",
+				},
+			}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).NotTo(HaveOccurred())
+
+			obj.Spec.JsonConfig = `{}`
+			_, err = validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.openAPI: Forbidden")))
+		})
+	})
+
+	Context("jsonConfig shape", func() {
+		newShaped := func(config string) *examplev1.JsonServer {
+			return &examplev1.JsonServer{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "app-shaped",
+				},
+				Spec: examplev1.JsonServerSpec{
+					JsonConfig: config,
+				},
+			}
+		}
+
+		It("should allow collections with related items", func() {
+			_, err := validator.ValidateCreate(ctx, newShaped(`{
+				"posts": [{ "id": 1, "title": "a" }, { "id": "2", "title": "b" }],
+				"comments": [{ "id": 1, "postId": 2 }, { "id": 2, "postId": null, "externalId": 9 }],
+				"profile": { "name": "typicode" }
+			}`))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should deny a top level that is not an object", func() {
+			for _, config := range []string{`[]`, `"string"`, `42`, `null`} {
+				_, err := validator.ValidateCreate(ctx, newShaped(config))
+				Expect(err).To(MatchError(ContainSubstring("spec.jsonConfig: Invalid value")), config)
+			}
+		})
+
+		It("should deny values that are not collections or objects", func() {
+			_, err := validator.ValidateCreate(ctx, newShaped(`{"people": [{ "id": 1 }, 2], "count": 3}`))
+			Expect(err).To(MatchError(ContainSubstring(`spec.jsonConfig.count: Invalid value: "number"`)))
+			Expect(err).To(MatchError(ContainSubstring(`spec.jsonConfig.people[1]: Invalid value: "number"`)))
+		})
+
+		It("should deny duplicated ids", func() {
+			_, err := validator.ValidateCreate(ctx, newShaped(
+				`{"people": [{ "id": 1 }, { "id": 2 }, { "id": 3 }, { "id": "1" }]}`))
+			Expect(err).To(MatchError(ContainSubstring(`spec.jsonConfig.people[3].id: Duplicate value: "1"`)))
+		})
+
+		It("should deny foreign keys to missing items", func() {
+			_, err := validator.ValidateCreate(ctx, newShaped(
+				`{"categories": [{ "id": 1 }], "posts": [{ "id": 1, "categoryId": 1 }, { "id": 2, "categoryId": 7 }]}`))
+			Expect(err).To(MatchError(ContainSubstring(`spec.jsonConfig.posts[1].categoryId: Not found: 7`)))
+		})
+
+		It("should use the configured id field and foreign key suffix", func() {
+			obj := newShaped(`{"users": [{ "_id": "a" }, { "_id": "a" }], "posts": [{ "_id": "p", "user_id": "b" }]}`)
+			obj.Spec.Server = &examplev1.ServerSpec{IDField: "_id", ForeignKeySuffix: "_id"}
+
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring(`spec.jsonConfig.users[1]._id: Duplicate value: "a"`)))
+			Expect(err).To(MatchError(ContainSubstring(`spec.jsonConfig.posts[0].user_id: Not found: "b"`)))
+		})
+	})
+
+	Context("Data formats", func() {
+		newFormatted := func(format examplev1.DataFormat, config string) *examplev1.JsonServer {
+			return &examplev1.JsonServer{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "app-formatted",
+				},
+				Spec: examplev1.JsonServerSpec{
+					JsonConfig: config,
+					DataFormat: format,
+				},
+			}
+		}
+		trailingComma := `{
+  "people": [
+    { "id": 1, "name": "Alice", }
+  ]
+}`
+
+		It("should report the line and column of JSON syntax errors", func() {
+			_, err := validator.ValidateCreate(ctx, newFormatted(examplev1.DataFormatJSON, trailingComma))
+			Expect(err).To(MatchError("Error: spec.jsonConfig is not valid json: " +
+				"line 3, column 33: invalid character '}' looking for beginning of object key string"))
+		})
+
+		It("should allow comments and trailing commas in jsonc", func() {
+			config := "// people of the demo\n" + trailingComma
+			_, err := validator.ValidateCreate(ctx, newFormatted(examplev1.DataFormatJSONC, config))
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = validator.ValidateCreate(ctx, newFormatted(examplev1.DataFormatJSONC, `{people: []}`))
+			Expect(err).To(MatchError("Error: spec.jsonConfig is not valid jsonc: " +
+				"line 1, column 2: unexpected character 'p', expected an object key"))
+		})
+
+		It("should convert json5 to JSON in key order", func() {
+			data, err := dataformat.Decode([]byte(`{
+				// JSON5 extensions
+				people: [{id: 0x1F, name: 'Ada', score: +.5, tags: ['a',],},],
+				"profile": {name: "typicode\
+ jr"},
+			}`), examplev1.DataFormatJSON5)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(data)).To(Equal(
+				`{"people":[{"id":31,"name":"Ada","score":0.5,"tags":["a"]}],"profile":{"name":"typicode jr"}}`))
+
+			_, err = dataformat.Decode([]byte("{\n  count: NaN\n}"), examplev1.DataFormatJSON5)
+			Expect(err).To(MatchError("line 2, column 10: NaN cannot be represented in JSON"))
+		})
+
+		It("should check the shape of YAML data", func() {
+			obj := newFormatted(examplev1.DataFormatYAML, `
+people:
+  - id: 1
+    name: Alice
+  - id: 1
+`)
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring(`spec.jsonConfig.people[1].id: Duplicate value: 1`)))
+
+			_, err = validator.ValidateCreate(ctx, newFormatted(examplev1.DataFormatYAML, "people: [1, 2\n"))
+			Expect(err).To(MatchError("Error: spec.jsonConfig is not valid yaml: line 1: did not find expected ',' or ']'"))
+		})
+
+		It("should match collection names against decoded data", func() {
+			obj := newFormatted(examplev1.DataFormatYAML, "people: []\n")
+			obj.Spec.Collections = []examplev1.CollectionSource{{Name: "people", Inline: `[]`}}
+
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring(`spec.collections[0].name: Duplicate value: "people"`)))
+		})
+
+		It("should allow an update to invalid data in any format", func() {
+			oldObj := newFormatted(examplev1.DataFormatYAML, "people: []\n")
+			newObj := newFormatted(examplev1.DataFormatYAML, "people: [\n")
+
+			_, err := validator.ValidateUpdate(ctx, oldObj, newObj)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should require json with openAPI", func() {
+			obj := newFormatted(examplev1.DataFormatYAML, "")
+			obj.Spec.OpenAPI = &examplev1.OpenAPISource{
+				ConfigMapKeyRef: corev1.ConfigMapKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "petstore"},
+					Key:                  "openapi.yaml",
+				},
+			}
+
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring(`spec.dataFormat: Invalid value: "yaml"`)))
+		})
+	})
+
+	Context("Schemas", func() {
+		personSchema := `{
+			"type": "object",
+			"required": ["name"],
+			"properties": {
+				"name": { "type": "string", "minLength": 2 },
+				"tags": { "type": "array", "items": { "type": "string" } }
+			}
+		}`
+		newSchemed := func(config string, enforcement examplev1.SchemaEnforcement) *examplev1.JsonServer {
+			return &examplev1.JsonServer{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "app-schemed",
+				},
+				Spec: examplev1.JsonServerSpec{
+					JsonConfig:        config,
+					Schemas:           map[string]examplev1.SchemaSource{"people": {Inline: personSchema}},
+					SchemaEnforcement: enforcement,
+				},
+			}
+		}
+		mismatched := `{"people": [{ "id": 1, "name": "Alice" }, { "id": 2, "name": "B", "tags": ["a", 1] }]}`
+
+		It("should allow items that match their schema", func() {
+			warnings, err := validator.ValidateCreate(ctx, newSchemed(
+				`{"people": [{ "id": 1, "name": "Alice", "tags": ["admin"] }]}`, examplev1.SchemaEnforcementDeny))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(BeEmpty())
+		})
+
+		It("should deny mismatching items with their JSON pointers", func() {
+			_, err := validator.ValidateCreate(ctx, newSchemed(mismatched, examplev1.SchemaEnforcementDeny))
+			Expect(err).To(MatchError(ContainSubstring(
+				"spec.jsonConfig.people[1].name: Invalid value: does not match the schema at /people/1/name")))
+			Expect(err).To(MatchError(ContainSubstring(
+				"spec.jsonConfig.people[1].tags[1]: Invalid value: does not match the schema at /people/1/tags/1")))
+
+			_, err = validator.ValidateCreate(ctx, newSchemed(`{"people": [{ "id": 1 }]}`, examplev1.SchemaEnforcementDeny))
+			Expect(err).To(MatchError(ContainSubstring("at /people/0/name: is required")))
+		})
+
+		It("should warn about mismatching items with Warn enforcement", func() {
+			warnings, err := validator.ValidateCreate(ctx, newSchemed(mismatched, examplev1.SchemaEnforcementWarn))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(HaveLen(2))
+			Expect(warnings[0]).To(ContainSubstring("/people/1/name"))
+		})
+
+		It("should deny schemas that cannot be parsed", func() {
+			obj := newSchemed(`{}`, examplev1.SchemaEnforcementDeny)
+			obj.Spec.Schemas["posts"] = examplev1.SchemaSource{Inline: `{ "type": `}
+
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.schemas[posts].inline")))
+		})
+	})
+
+	Context("Collections", func() {
+		newComposed := func(config string, collections ...examplev1.CollectionSource) *examplev1.JsonServer {
+			return &examplev1.JsonServer{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "app-composed",
+				},
+				Spec: examplev1.JsonServerSpec{
+					JsonConfig:  config,
+					Collections: collections,
+				},
+			}
+		}
+		fromConfigMap := examplev1.CollectionSource{
+			Name: "posts",
+			ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "fixtures"},
+				Key:                  "posts.json",
+			},
+		}
+
+		It("should warn about CSV and NDJSON rows that are skipped", func() {
+			warnings, err := validator.ValidateCreate(ctx, newComposed("",
+				examplev1.CollectionSource{Name: "users", Format: examplev1.CollectionFormatCSV,
+					Inline: "id,name\n1,Ada\n2,Grace,extra\n"},
+				examplev1.CollectionSource{Name: "events", Format: examplev1.CollectionFormatNDJSON,
+					Inline: `{"id": 1}` + "\n" + `{"id": 2}` + "\n"}))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(ConsistOf(
+				"spec.collections[0].inline: 1 rows are skipped, the first at line 3: has 3 fields, the header row has 2"))
+		})
+
+		It("should deny CSV that cannot be read", func() {
+			_, err := validator.ValidateCreate(ctx, newComposed("",
+				examplev1.CollectionSource{Name: "users", Format: examplev1.CollectionFormatCSV,
+					Inline: "id,\"name\n1,Ada\n"}))
+			Expect(err).To(MatchError(ContainSubstring(`spec.collections[0].inline: Invalid value: "<invalid csv>": line 2, column 7`)))
+		})
+
+		It("should allow db.json made of collections only", func() {
+			_, err := validator.ValidateCreate(ctx, newComposed("",
+				examplev1.CollectionSource{Name: "users", Inline: `[{"id":1}]`},
+				fromConfigMap,
+			))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should deny duplicate collection names", func() {
+			_, err := validator.ValidateCreate(ctx, newComposed("",
+				examplev1.CollectionSource{Name: "posts", Inline: `[]`},
+				fromConfigMap,
+			))
+			Expect(err).To(MatchError(ContainSubstring(`spec.collections[1].name: Duplicate value: "posts"`)))
+
+			_, err = validator.ValidateCreate(ctx, newComposed(`{"posts":[]}`, fromConfigMap))
+			Expect(err).To(MatchError(ContainSubstring(`spec.collections[0].name: Duplicate value: "posts"`)))
+		})
+
+		It("should check the templates of generated collections", func() {
+			obj := newComposed("", fromConfigMap)
+			obj.Spec.Generate = []examplev1.GenerateSpec{{
+				Name:  "users",
+				Count: 1000,
+				Fields: map[string]string{
+					"name":  "{{name}}",
+					"age":   "{{int 18 90}}",
+					"email": "user-{{index}}@example.com",
+				},
+			}}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).NotTo(HaveOccurred())
+
+			obj.Spec.Generate[0].Name = "posts"
+			obj.Spec.Generate[0].Fields["id"] = "{{uuid}}"
+			obj.Spec.Generate[0].Fields["age"] = "{{int 90 18}}"
+			obj.Spec.Generate[0].Fields["nick"] = "{{nickname}}"
+			_, err = validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring(`spec.generate[0].name: Duplicate value: "posts"`)))
+			Expect(err).To(MatchError(ContainSubstring("spec.generate[0].fields[id]: Forbidden")))
+			Expect(err).To(MatchError(ContainSubstring("maximum is less than minimum")))
+			Expect(err).To(MatchError(ContainSubstring(`unknown placeholder "nickname"`)))
+		})
+
+		It("should deny promoting a snapshot while collections or generate is set", func() {
+			obj := newComposed(`{"people":[]}`, fromConfigMap)
+			obj.Annotations = map[string]string{examplev1.PromoteSnapshotAnnotation: "app-composed-snapshot"}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring(
+				"snapshots cannot be promoted while spec.collections or spec.generate is set")))
+
+			By("checking the annotation when it is added on its own")
+			oldObj := newComposed(`{"people":[]}`, fromConfigMap)
+			_, err = validator.ValidateUpdate(ctx, oldObj, obj)
+			Expect(err).To(MatchError(ContainSubstring(
+				"metadata.annotations[json-server.example.com/promote-snapshot]: Forbidden")))
+
+			obj.Spec.Collections = nil
+			oldObj.Spec.Collections = nil
+			_, err = validator.ValidateUpdate(ctx, oldObj, obj)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should deny collections without exactly one valid source", func() {
+			_, err := validator.ValidateCreate(ctx, newComposed("",
+				examplev1.CollectionSource{Name: "users"}))
+			Expect(err).To(MatchError(ContainSubstring("exactly one of inline and configMapKeyRef")))
+
+			_, err = validator.ValidateCreate(ctx, newComposed("",
+				examplev1.CollectionSource{Name: "users", Inline: `42`}))
+			Expect(err).To(MatchError(ContainSubstring("spec.collections[0].inline")))
+		})
+	})
+
+	Context("Large jsonConfig", func() {
+		newSized := func(config string) *examplev1.JsonServer {
+			return &examplev1.JsonServer{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "app-large",
+				},
+				Spec: examplev1.JsonServerSpec{
+					JsonConfig: config,
+				},
+			}
+		}
+
+		It("should allow data that fits into the ConfigMap once compressed", func() {
+			// Items without ids get them from json-server.
+			item := `{"name":"Alice","email":"alice@example.com","active":1},`
+			config := `{"people":[` + strings.Repeat(item, 22000) + `{"id":0}]}`
+			Expect(len(config)).To(BeNumerically(">", examplev1.MaxConfigMapBytes))
+
+			_, err := validator.ValidateCreate(ctx, newSized(config))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should deny data that does not fit even when compressed", func() {
+			blob := make([]byte, 2*examplev1.MaxConfigMapBytes)
+			_, _ = rand.Read(blob)
+			config := `{"blob":{"data":"` + base64.StdEncoding.EncodeToString(blob) + `"}}`
+
+			_, err := validator.ValidateCreate(ctx, newSized(config))
+			Expect(err).To(MatchError(ContainSubstring("spec.jsonConfig")))
+			Expect(err.Error()).To(ContainSubstring("gzip-compressed"))
+		})
+	})
+
+	Context("Ingress", func() {
+		newExposed := func(ingress *examplev1.IngressSpec) *examplev1.JsonServer {
+			return &examplev1.JsonServer{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "app-exposed",
+				},
+				Spec: examplev1.JsonServerSpec{
+					JsonConfig: `{}`,
+					Ingress:    ingress,
+				},
+			}
+		}
+
+		It("should allow an Ingress with TLS", func() {
+			_, err := validator.ValidateCreate(ctx, newExposed(&examplev1.IngressSpec{
+				Host:          "people.mock.example.com",
+				Path:          "/api",
+				TLSSecretName: "mock-tls",
+			}))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should deny an invalid host and Ingress-only fields with a Gateway", func() {
+			className := "nginx"
+			_, err := validator.ValidateCreate(ctx, newExposed(&examplev1.IngressSpec{
+				Host:          "People_Mock",
+				ClassName:     &className,
+				TLSSecretName: "mock-tls",
+				Gateway:       &examplev1.GatewayReference{Name: "public"},
+			}))
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.ingress.host"))
+			Expect(err.Error()).To(ContainSubstring("spec.ingress.tlsSecretName"))
+			Expect(err.Error()).To(ContainSubstring("spec.ingress.className"))
+		})
+	})
+
+	Context("Replica consistency", func() {
+		newScaled := func(mode examplev1.ConsistencyMode, storage bool) *examplev1.JsonServer {
+			replicas := int32(3)
+			obj := &examplev1.JsonServer{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "app-scaled",
+				},
+				Spec: examplev1.JsonServerSpec{
+					Replicas:    &replicas,
+					JsonConfig:  `{}`,
+					Consistency: &examplev1.ConsistencySpec{Mode: mode},
+				},
+			}
+			if storage {
+				obj.Spec.Storage = &examplev1.StorageSpec{}
+			}
+			return obj
+		}
+
+		It("should warn when independent replicas diverge", func() {
+			warnings, err := validator.ValidateCreate(ctx, newScaled(examplev1.ConsistencyIndependent, false))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(HaveLen(1))
+		})
+
+		It("should warn that scaled replicas are read-only", func() {
+			warnings, err := validator.ValidateCreate(ctx, newScaled(examplev1.ConsistencyReadOnlyWhenScaled, false))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(ContainElement(ContainSubstring("--read-only")))
+		})
+
+		It("should deny scaled replicas sharing storage unless a single writer holds it", func() {
+			_, err := validator.ValidateCreate(ctx, newScaled(examplev1.ConsistencyIndependent, true))
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.replicas"))
+
+			warnings, err := validator.ValidateCreate(ctx, newScaled(examplev1.ConsistencySingleWriter, true))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(BeEmpty())
+		})
+
+		It("should reserve generated middleware file names", func() {
+			obj := newScaled(examplev1.ConsistencySingleWriter, false)
+			obj.Spec.Server = &examplev1.ServerSpec{
+				Middlewares: map[string]string{"json-server-forward-writes.js": "module.exports = () => {}"},
+			}
+
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(HaveOccurred())
+		})
 	})
 
 	Context("ValidateUpdate", func() {
@@ -93,5 +744,207 @@ var _ = Describe("JsonServer Webhook", func() {
 			_, err := validator.ValidateUpdate(ctx, oldObj, newObj)
 			Expect(err).NotTo(HaveOccurred())
 		})
+
+		It("should deny update with invalid server options", func() {
+			oldObj := &examplev1.JsonServer{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "app-test",
+				},
+				Spec: examplev1.JsonServerSpec{
+					JsonConfig: `{}`,
+				},
+			}
+
+			newObj := oldObj.DeepCopy()
+			newObj.Spec.Server = &examplev1.ServerSpec{ForeignKeySuffix: "-id"}
+
+			_, err := validator.ValidateUpdate(ctx, oldObj, newObj)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should allow growing storage but deny shrinking it or changing its class", func() {
+			fast, slow := "fast", "slow"
+			oldObj := &examplev1.JsonServer{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "app-test",
+				},
+				Spec: examplev1.JsonServerSpec{
+					JsonConfig: `{}`,
+					Storage: &examplev1.StorageSpec{
+						StorageClassName: &fast,
+						Size:             resource.MustParse("1Gi"),
+					},
+				},
+			}
+
+			grown := oldObj.DeepCopy()
+			grown.Spec.Storage.Size = resource.MustParse("2Gi")
+			_, err := validator.ValidateUpdate(ctx, oldObj, grown)
+			Expect(err).NotTo(HaveOccurred())
+
+			shrunk := oldObj.DeepCopy()
+			shrunk.Spec.Storage.Size = resource.MustParse("500Mi")
+			_, err = validator.ValidateUpdate(ctx, oldObj, shrunk)
+			Expect(err).To(HaveOccurred())
+
+			moved := oldObj.DeepCopy()
+			moved.Spec.Storage.StorageClassName = &slow
+			_, err = validator.ValidateUpdate(ctx, oldObj, moved)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should skip spec checks for metadata-only updates and deletions", func() {
+			// Admitted before the checks got stricter.
+			oldObj := &examplev1.JsonServer{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "app-test",
+				},
+				Spec: examplev1.JsonServerSpec{
+					JsonConfig: `{}`,
+					Server:     &examplev1.ServerSpec{ForeignKeySuffix: "-id"},
+				},
+			}
+
+			withFinalizer := oldObj.DeepCopy()
+			withFinalizer.Finalizers = []string{examplev1.Finalizer}
+			examplev1.SetDefaults(withFinalizer)
+			_, err := validator.ValidateUpdate(ctx, oldObj, withFinalizer)
+			Expect(err).NotTo(HaveOccurred())
+
+			deleting := withFinalizer.DeepCopy()
+			now := metav1.Now()
+			deleting.DeletionTimestamp = &now
+			deleting.Finalizers = nil
+			deleting.Spec.Replicas = nil
+			_, err = validator.ValidateUpdate(ctx, withFinalizer, deleting)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("still checking spec changes")
+			changed := withFinalizer.DeepCopy()
+			changed.Spec.Port = 8080
+			_, err = validator.ValidateUpdate(ctx, withFinalizer, changed)
+			Expect(err).To(MatchError(ContainSubstring("spec.server.foreignKeySuffix")))
+		})
+	})
+
+	Context("ValidateDelete", func() {
+		newDeleted := func(namespace string) *examplev1.JsonServer {
+			return &examplev1.JsonServer{
+				ObjectMeta: metav1.ObjectMeta{Name: "app-mock", Namespace: namespace},
+				Spec:       examplev1.JsonServerSpec{JsonConfig: `{}`},
+			}
+		}
+
+		It("should allow deleting unprotected JsonServers", func() {
+			_, err := validator.ValidateDelete(ctx, newDeleted("default"))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should deny deleting JsonServers protected by the spec or the annotation", func() {
+			obj := newDeleted("default")
+			obj.Spec.DeletionProtection = true
+			_, err := validator.ValidateDelete(ctx, obj)
+			Expect(apierrors.IsForbidden(err)).To(BeTrue())
+			Expect(err).To(MatchError(ContainSubstring("deletion protection is enabled by spec.deletionProtection")))
+
+			obj = newDeleted("default")
+			obj.Annotations = map[string]string{examplev1.ProtectedAnnotation: "true"}
+			_, err = validator.ValidateDelete(ctx, obj)
+			Expect(apierrors.IsForbidden(err)).To(BeTrue())
+			Expect(err).To(MatchError(ContainSubstring(examplev1.ProtectedAnnotation + " annotation")))
+
+			obj.Annotations[examplev1.ProtectedAnnotation] = "false"
+			_, err = validator.ValidateDelete(ctx, obj)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should deny deleting JsonServers in namespaces that require protection", func() {
+			validator.Reader = fake.NewClientBuilder().WithObjects(
+				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+					Name:   "staging",
+					Labels: map[string]string{examplev1.DeletionProtectionLabel: examplev1.DeletionProtectionRequired},
+				}},
+				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "dev"}},
+			).Build()
+
+			_, err := validator.ValidateDelete(ctx, newDeleted("staging"))
+			Expect(apierrors.IsForbidden(err)).To(BeTrue())
+			Expect(err).To(MatchError(ContainSubstring("namespace staging, labelled " +
+				examplev1.DeletionProtectionLabel + "=required")))
+
+			_, err = validator.ValidateDelete(ctx, newDeleted("dev"))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should allow deleting protected JsonServers with their namespace", func() {
+			validator.Reader = fake.NewClientBuilder().WithObjects(
+				&corev1.Namespace{
+					ObjectMeta: metav1.ObjectMeta{Name: "staging"},
+					Status:     corev1.NamespaceStatus{Phase: corev1.NamespaceTerminating},
+				},
+			).Build()
+
+			obj := newDeleted("staging")
+			obj.Spec.DeletionProtection = true
+			_, err := validator.ValidateDelete(ctx, obj)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Context("Default", func() {
+		It("should fill in the effective spec", func() {
+			obj := &examplev1.JsonServer{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "app-defaults",
+				},
+				Spec: examplev1.JsonServerSpec{
+					JsonConfig: `{}`,
+					Storage:    &examplev1.StorageSpec{},
+				},
+			}
+
+			defaulter := JsonServerCustomDefaulter{}
+			Expect(defaulter.Default(ctx, obj)).To(Succeed())
+
+			Expect(obj.Labels).To(HaveKeyWithValue("app.kubernetes.io/instance", "app-defaults"))
+			Expect(*obj.Spec.Replicas).To(Equal(int32(1)))
+			Expect(obj.Spec.Port).To(Equal(int32(3000)))
+			Expect(obj.Spec.Image).To(BeNil(), "the image follows the operator default")
+			Expect(obj.Spec.Server.IDField).To(Equal("id"))
+			Expect(obj.Spec.Server.ForeignKeySuffix).To(Equal("Id"))
+			Expect(obj.Spec.Storage.Size.String()).To(Equal("1Gi"))
+			Expect(obj.Spec.Storage.ReseedPolicy).To(Equal(examplev1.ReseedOnConfigChange))
+			Expect(obj.Spec.Consistency.Mode).To(Equal(examplev1.ConsistencyIndependent))
+			Expect(*obj.Spec.Service).To(Equal(examplev1.ServiceSpec{Type: corev1.ServiceTypeClusterIP, Port: 3000}))
+			Expect(obj.Spec.DataFormat).To(Equal(examplev1.DataFormatJSON))
+			Expect(obj.Spec.DeletionPolicy).To(Equal(examplev1.DeletionPolicyDelete))
+
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should keep values that are already set", func() {
+			replicas := int32(0)
+			obj := &examplev1.JsonServer{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "app-defaults",
+					Labels: map[string]string{"app.kubernetes.io/name": "mock-api"},
+				},
+				Spec: examplev1.JsonServerSpec{
+					Replicas:    &replicas,
+					JsonConfig:  `{}`,
+					Image:       &examplev1.ImageSpec{Tag: "latest"},
+					Consistency: &examplev1.ConsistencySpec{Mode: examplev1.ConsistencySingleWriter},
+				},
+			}
+
+			defaulter := JsonServerCustomDefaulter{}
+			Expect(defaulter.Default(ctx, obj)).To(Succeed())
+
+			Expect(obj.Labels).To(HaveKeyWithValue("app.kubernetes.io/name", "mock-api"))
+			Expect(*obj.Spec.Replicas).To(Equal(int32(0)))
+			Expect(*obj.Spec.Image).To(Equal(examplev1.ImageSpec{Tag: "latest"}))
+			Expect(obj.Spec.Consistency.SyncIntervalSeconds).To(Equal(int32(5)))
+		})
 	})
 })
@@ -24,6 +24,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	examplev1 "github.com/BlueTurtle-bytes/json-server/api/v1"
+	examplev2 "github.com/BlueTurtle-bytes/json-server/api/v2"
 )
 
 var (
@@ -45,6 +46,7 @@ var _ = BeforeSuite(func() {
 	ctx, cancel = context.WithCancel(context.Background())
 
 	Expect(examplev1.AddToScheme(scheme.Scheme)).To(Succeed())
+	Expect(examplev2.AddToScheme(scheme.Scheme)).To(Succeed())
 
 	By("bootstrapping webhook test environment")
 	testEnv = &envtest.Environment{
@@ -84,7 +86,7 @@ var _ = BeforeSuite(func() {
 	})
 	Expect(err).NotTo(HaveOccurred())
 
-	Expect(SetupJsonServerWebhookWithManager(mgr)).To(Succeed())
+	Expect(SetupJsonServerWebhookWithManager(mgr, Options{})).To(Succeed())
 
 	go func() {
 		defer GinkgoRecover()